@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/seeg/github-fork-manager/internal/config"
+	"github.com/seeg/github-fork-manager/internal/gh"
+	"github.com/seeg/github-fork-manager/internal/policy"
+	"github.com/seeg/github-fork-manager/internal/report"
+	"github.com/seeg/github-fork-manager/internal/selection"
+)
+
+// deleteCommand implements `delete --from-file list --yes --confirm phrase`,
+// the non-interactive counterpart of loading a list with --select-from and
+// pressing d. Every entry must match a repo or nothing is deleted. Forks
+// that back open pull requests or hold unique commits are skipped unless
+// --force is passed.
+func deleteCommand(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("delete", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fromFile := fs.String("from-file", "", "file listing repos to delete: full names, URLs or IDs, one per line (- for stdin)")
+	yes := fs.Bool("yes", false, "required: confirm that the listed repos should be deleted")
+	confirm := fs.String("confirm", "", `required: the approval phrase "<login> approves"`)
+	force := fs.Bool("force", false, "also delete forks that back open pull requests or may hold unique commits")
+	dryRun := fs.Bool("dry-run", false, "only report what would be deleted (overrides dry_run in config)")
+	profile := fs.String("profile", "", "use the named profile from config.json")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *fromFile == "" {
+		fmt.Fprintln(stderr, "delete: --from-file is required")
+		return exitUsage
+	}
+	if !*yes {
+		fmt.Fprintln(stderr, "delete: refusing to delete without --yes")
+		return exitUsage
+	}
+	entries, err := readList(*fromFile, stdin)
+	if err != nil {
+		fmt.Fprintf(stderr, "delete: %v\n", err)
+		return exitUsage
+	}
+
+	cfg, err := config.LoadProfile(*profile)
+	if err != nil {
+		fmt.Fprintf(stderr, "config error: %v\n", err)
+		return exitFailed
+	}
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "dry-run" {
+			cfg.DryRun = *dryRun
+		}
+	})
+	client, err := newProvider(cfg)
+	if err != nil {
+		fmt.Fprintf(stderr, "config error: %v\n", err)
+		return exitFailed
+	}
+	if cfg.DryRun {
+		client = gh.DryRun(client)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	login, err := client.CurrentUser(ctx)
+	if err != nil {
+		fmt.Fprintf(stderr, "whoami: %v\n", err)
+		return exitFailed
+	}
+	if want := approvalPhrase(login); *confirm != want {
+		fmt.Fprintf(stderr, "delete: --confirm must be %q\n", want)
+		return exitUsage
+	}
+
+	var repos []gh.Repo
+	for _, forks := range []bool{true, false} {
+		batch, err := client.FetchRepos(ctx, forks)
+		if err != nil {
+			fmt.Fprintf(stderr, "list repos: %v\n", err)
+			return exitFailed
+		}
+		repos = append(repos, batch...)
+	}
+	matched, unmatched := selection.Match(entries, repos)
+	if len(unmatched) > 0 {
+		for _, e := range unmatched {
+			fmt.Fprintf(stderr, "no match: %s\n", e)
+		}
+		fmt.Fprintf(stderr, "%d of %d entries matched no repo; nothing deleted\n", len(unmatched), len(entries))
+		return exitUsage
+	}
+
+	var guard deleteGuard
+	if !*force {
+		if guard, err = newDeleteGuard(ctx, client, login, uniqueScan(client)); err != nil {
+			fmt.Fprintf(stderr, "%v\n", err)
+			return exitFailed
+		}
+	}
+
+	failed, skipped := 0, 0
+	var history []report.Entry
+	for _, repo := range matched {
+		name := repo.FullName
+		if reason, result := guard.check(repo); reason != "" {
+			fmt.Fprintf(stdout, "skip     %s (%s; --force to delete)\n", name, reason)
+			logLine(cfg.LogPath, fmt.Sprintf("delete %s -> %s", name, result))
+			history = append(history, report.NewEntry(repo, result, time.Now()))
+			skipped++
+			continue
+		}
+
+		delCtx, delCancel := context.WithTimeout(context.Background(), 20*time.Second)
+		err := client.DeleteRepo(delCtx, name)
+		delCancel()
+		result := actionResult(policy.ActionDelete, err, cfg.DryRun)
+		if err != nil && !errors.Is(err, gh.ErrDeleteScheduled) {
+			failed++
+		}
+		fmt.Fprintf(stdout, "delete   %s -> %s\n", name, result)
+		logLine(cfg.LogPath, fmt.Sprintf("delete %s -> %s", name, result))
+		history = append(history, report.NewEntry(repo, result, time.Now()))
+	}
+	if !cfg.DryRun {
+		saveLastBatch(cfg, "Delete from "+*fromFile, history)
+	}
+
+	if skipped > 0 || failed > 0 {
+		fmt.Fprintf(stderr, "%d of %d repos not deleted (%d skipped, %d failed)\n", skipped+failed, len(matched), skipped, failed)
+		return exitFailed
+	}
+	return exitOK
+}
+
+// deleteGuard decides which forks unattended deletes leave alone: those
+// that back open pull requests, which deleting would close, and those that
+// may hold unique commits. The zero guard lets everything through.
+type deleteGuard struct {
+	prs    map[string][]gh.PullRequest
+	unique func(gh.Repo) gh.Uniqueness
+}
+
+// newDeleteGuard looks up the open pull requests of login. unique may be
+// nil when the provider cannot scan forks.
+func newDeleteGuard(ctx context.Context, client gh.Provider, login string, unique func(gh.Repo) gh.Uniqueness) (deleteGuard, error) {
+	g := deleteGuard{unique: unique}
+	if finder, ok := gh.Lookup[gh.PullRequestFinder](client); ok {
+		prs, err := finder.OpenPullRequestsByFork(ctx, login)
+		if err != nil {
+			return deleteGuard{}, fmt.Errorf("could not check open pull requests: %w", err)
+		}
+		g.prs = prs
+	}
+	return g, nil
+}
+
+// check returns why repo must not be deleted and the result to record for
+// it, or empty strings when it may go.
+func (g deleteGuard) check(repo gh.Repo) (reason, result string) {
+	if n := len(g.prs[repo.FullName]); n > 0 {
+		return fmt.Sprintf("backs %d open pull requests", n), "skipped: open pull requests"
+	}
+	if g.unique != nil && repo.Fork {
+		if u := g.unique(repo); u.State != gh.UniqueNone {
+			return u.String(), "skipped: " + u.String()
+		}
+	}
+	return "", ""
+}
+
+// uniqueScan returns a unique-commit scan of client that remembers its
+// results, or nil when the provider cannot scan.
+func uniqueScan(client gh.Provider) func(gh.Repo) gh.Uniqueness {
+	scanner, ok := gh.Lookup[gh.UniquenessScanner](client)
+	if !ok {
+		return nil
+	}
+	seen := make(map[string]gh.Uniqueness)
+	return func(repo gh.Repo) gh.Uniqueness {
+		if u, ok := seen[repo.FullName]; ok {
+			return u
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		defer cancel()
+		u := scanner.ScanUniqueness(ctx, repo)
+		seen[repo.FullName] = u
+		return u
+	}
+}
+
+// readList reads a repo list from path, or from stdin when path is "-".
+func readList(path string, stdin io.Reader) ([]string, error) {
+	if path == "-" {
+		return selection.ParseList(stdin)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return selection.ParseList(f)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/seeg/github-fork-manager/internal/ghfake"
+)
+
+// deleteEnv points config loading at srv and writes list to a file,
+// returning its path and the action log path.
+func deleteEnv(t *testing.T, srv *ghfake.Server, list string) (string, string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GITHUB_TOKEN", "token")
+	t.Setenv("GITHUB_API_BASE", srv.Start())
+	t.Cleanup(srv.Close)
+	p := filepath.Join(home, "list.txt")
+	if err := os.WriteFile(p, []byte(list), 0o644); err != nil {
+		t.Fatalf("write list: %v", err)
+	}
+	return p, filepath.Join(home, ".github-fork-manager", "actions.log")
+}
+
+func listServer() *ghfake.Server {
+	srv := ghfake.New("me")
+	srv.AddRepo(ghfake.Repo{Owner: "up", Name: "lib"})
+	srv.AddRepo(ghfake.Repo{ID: 40, Owner: "me", Name: "a", Parent: "up/lib"})
+	srv.AddRepo(ghfake.Repo{ID: 41, Owner: "me", Name: "b", Parent: "up/lib"})
+	srv.AddRepo(ghfake.Repo{ID: 42, Owner: "me", Name: "site"})
+	return srv
+}
+
+func TestDeleteFromFileRequiresYesAndPhrase(t *testing.T) {
+	srv := listServer()
+	list, _ := deleteEnv(t, srv, "me/a\n")
+	var stdout, stderr bytes.Buffer
+
+	if code := deleteCommand([]string{"--from-file", list, "--confirm", "me approves"}, nil, &stdout, &stderr); code != exitUsage {
+		t.Fatalf("expected usage error without --yes, got %d", code)
+	}
+	stderr.Reset()
+	if code := deleteCommand([]string{"--from-file", list, "--yes", "--confirm", "yes"}, nil, &stdout, &stderr); code != exitUsage {
+		t.Fatalf("expected usage error for wrong phrase, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), `"me approves"`) {
+		t.Fatalf("expected expected phrase in error, got %q", stderr.String())
+	}
+	if _, ok := srv.Repo("me/a"); !ok {
+		t.Fatalf("repo deleted without full confirmation")
+	}
+}
+
+func TestDeleteFromFileMatchesNamesURLsAndIDs(t *testing.T) {
+	srv := listServer()
+	list, logPath := deleteEnv(t, srv, "https://github.com/me/a\n42\n")
+	var stdout, stderr bytes.Buffer
+
+	code := deleteCommand([]string{"--from-file", list, "--yes", "--confirm", "me approves"}, nil, &stdout, &stderr)
+	if code != exitOK {
+		t.Fatalf("expected success, got %d: %s", code, stderr.String())
+	}
+	for _, name := range []string{"me/a", "me/site"} {
+		if _, ok := srv.Repo(name); ok {
+			t.Fatalf("expected %s deleted", name)
+		}
+	}
+	if _, ok := srv.Repo("me/b"); !ok {
+		t.Fatalf("unlisted repo was deleted")
+	}
+	logData, _ := os.ReadFile(logPath)
+	if !strings.Contains(string(logData), "delete me/site -> deleted") {
+		t.Fatalf("expected log entry, got %q", logData)
+	}
+}
+
+func TestDeleteFromStdinAbortsOnUnmatched(t *testing.T) {
+	srv := listServer()
+	deleteEnv(t, srv, "")
+	var stdout, stderr bytes.Buffer
+
+	stdin := strings.NewReader("me/a\nme/gone\n")
+	code := deleteCommand([]string{"--from-file", "-", "--yes", "--confirm", "me approves"}, stdin, &stdout, &stderr)
+	if code != exitUsage || !strings.Contains(stderr.String(), "no match: me/gone") {
+		t.Fatalf("expected unmatched report, got %d: %s", code, stderr.String())
+	}
+	if _, ok := srv.Repo("me/a"); !ok {
+		t.Fatalf("nothing may be deleted when entries are unmatched")
+	}
+}
+
+func TestDeleteFromFileSkipsGuardedForks(t *testing.T) {
+	srv := listServer()
+	srv.AddPullRequest(ghfake.PullRequest{Base: "up/lib", Author: "me", Head: "me/a", Title: "Fix"})
+	srv.Fail(ghfake.Failure{Method: http.MethodDelete, Path: "/repos/me/b", Status: http.StatusForbidden})
+	list, logPath := deleteEnv(t, srv, "me/a\nme/b\n")
+	var stdout, stderr bytes.Buffer
+
+	code := deleteCommand([]string{"--from-file", list, "--yes", "--confirm", "me approves"}, nil, &stdout, &stderr)
+	if code != exitFailed {
+		t.Fatalf("expected failure exit, got %d", code)
+	}
+	if logData, _ := os.ReadFile(logPath); !strings.Contains(string(logData), "delete me/a -> skipped: ") {
+		t.Fatalf("expected the skip in the action log, got %q", logData)
+	}
+	if !strings.Contains(stdout.String(), "skip     me/a (backs 1 open pull requests") {
+		t.Fatalf("expected PR fork skipped, got %q", stdout.String())
+	}
+	if !strings.Contains(stdout.String(), "delete   me/b -> error:") {
+		t.Fatalf("expected failed delete reported, got %q", stdout.String())
+	}
+	if _, ok := srv.Repo("me/a"); !ok {
+		t.Fatalf("fork with open PR was deleted")
+	}
+}
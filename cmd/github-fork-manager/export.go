@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/seeg/github-fork-manager/internal/config"
+	"github.com/seeg/github-fork-manager/internal/gh"
+	"github.com/seeg/github-fork-manager/internal/report"
+	"github.com/seeg/github-fork-manager/internal/selection"
+)
+
+// exportCommand implements `export`: it writes the last batch recorded by
+// the TUI, run or delete, or a saved selection, as a Markdown, CSV or JSON
+// report.
+func exportCommand(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	selectionName := fs.String("selection", "", "export the named saved selection instead of the last batch")
+	format := fs.String("format", "", "markdown, csv or json (default: from the --output extension, else markdown)")
+	output := fs.String("output", "-", "file to write, - for stdout")
+	profile := fs.String("profile", "", "use the named profile from config.json")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	f := report.FormatForPath(*output)
+	if *format != "" {
+		var err error
+		if f, err = report.ParseFormat(*format); err != nil {
+			fmt.Fprintf(stderr, "export: %v\n", err)
+			return exitUsage
+		}
+	}
+
+	cfg, err := config.LoadProfile(*profile)
+	if err != nil {
+		fmt.Fprintf(stderr, "config error: %v\n", err)
+		return exitFailed
+	}
+
+	var rep report.Report
+	if *selectionName != "" {
+		rep, err = selectionReport(cfg, *selectionName, stderr)
+	} else {
+		rep, err = readLastBatch(cfg)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "export: %v\n", err)
+		return exitFailed
+	}
+
+	if *output == "-" {
+		if err := report.Write(stdout, f, rep); err != nil {
+			fmt.Fprintf(stderr, "export: %v\n", err)
+			return exitFailed
+		}
+		return exitOK
+	}
+	if err := writeReport(*output, f, rep); err != nil {
+		fmt.Fprintf(stderr, "export: %v\n", err)
+		return exitFailed
+	}
+	fmt.Fprintf(stderr, "wrote %d repos to %s\n", len(rep.Entries), *output)
+	return exitOK
+}
+
+// selectionReport describes the repos of a saved selection with current
+// metadata. Repos that no longer exist are reported on stderr.
+func selectionReport(cfg config.Config, name string, stderr io.Writer) (report.Report, error) {
+	set, err := selection.Store{Dir: cfg.SelectionsDir}.Load(name)
+	if err != nil {
+		return report.Report{}, err
+	}
+	client, err := newProvider(cfg)
+	if err != nil {
+		return report.Report{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	byName := make(map[string]gh.Repo)
+	for _, forks := range []bool{true, false} {
+		repos, err := client.FetchRepos(ctx, forks)
+		if err != nil {
+			return report.Report{}, fmt.Errorf("list repos: %w", err)
+		}
+		for _, r := range repos {
+			byName[r.FullName] = r
+		}
+	}
+	var entries []report.Entry
+	for _, fullName := range set.Selected {
+		repo, ok := byName[fullName]
+		if !ok {
+			fmt.Fprintf(stderr, "no longer exists: %s\n", fullName)
+			continue
+		}
+		entries = append(entries, report.NewEntry(repo, "", time.Time{}))
+	}
+	return report.New(fmt.Sprintf("Saved selection %q", name), entries, time.Now()), nil
+}
+
+// lastBatchPath is where the most recent batch is kept for export. It sits
+// next to the action log; without a log nothing is kept.
+func lastBatchPath(cfg config.Config) string {
+	if cfg.LogPath == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(cfg.LogPath), "last-batch.json")
+}
+
+// saveLastBatch records entries as the most recent batch. Failures are
+// ignored like action log failures: the action itself already happened.
+func saveLastBatch(cfg config.Config, title string, entries []report.Entry) {
+	path := lastBatchPath(cfg)
+	if path == "" || len(entries) == 0 {
+		return
+	}
+	_ = writeReport(path, report.JSON, report.New(title, entries, time.Now()))
+}
+
+func readLastBatch(cfg config.Config) (report.Report, error) {
+	path := lastBatchPath(cfg)
+	if path == "" {
+		return report.Report{}, fmt.Errorf("no action log configured, so no batch was recorded")
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return report.Report{}, fmt.Errorf("no batch recorded yet in %s", path)
+	}
+	if err != nil {
+		return report.Report{}, err
+	}
+	defer f.Close()
+	return report.Read(f)
+}
+
+// writeReport writes rep to path, replacing it atomically.
+func writeReport(path string, f report.Format, rep report.Report) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	if err := report.Write(tmp, f, rep); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/seeg/github-fork-manager/internal/ghfake"
+)
+
+func TestExportLastBatchAfterDelete(t *testing.T) {
+	srv := listServer()
+	list, _ := deleteEnv(t, srv, "me/a\nme/site\n")
+	var stdout, stderr bytes.Buffer
+
+	var out bytes.Buffer
+	if code := exportCommand(nil, &out, &stderr); code != exitFailed || !strings.Contains(stderr.String(), "no batch recorded yet") {
+		t.Fatalf("expected missing batch error, got %d: %s", code, stderr.String())
+	}
+
+	if code := deleteCommand([]string{"--from-file", list, "--yes", "--confirm", "me approves"}, nil, &stdout, &stderr); code != exitOK {
+		t.Fatalf("delete failed: %s", stderr.String())
+	}
+	out.Reset()
+	if code := exportCommand([]string{"--format", "md"}, &out, &stderr); code != exitOK {
+		t.Fatalf("export failed: %s", stderr.String())
+	}
+	for _, want := range []string{"# Delete from " + list, "- Repos: 2", "- deleted: 2", "| me/a | deleted |"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("report missing %q:\n%s", want, out.String())
+		}
+	}
+
+	csvPath := filepath.Join(t.TempDir(), "report.csv")
+	if code := exportCommand([]string{"--output", csvPath}, &out, &stderr); code != exitOK {
+		t.Fatalf("export failed: %s", stderr.String())
+	}
+	data, _ := os.ReadFile(csvPath)
+	if !strings.Contains(string(data), "# deleted: 2") || !strings.Contains(string(data), "full_name,result,parent") {
+		t.Fatalf("unexpected csv:\n%s", data)
+	}
+}
+
+func TestExportKeyWritesSelection(t *testing.T) {
+	srv := ghfake.New("me")
+	srv.AddRepo(ghfake.Repo{Owner: "up", Name: "lib"})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "one", Parent: "up/lib", Size: 300, PushedAt: time.Now()})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "two", Parent: "up/lib"})
+
+	m := newFakeModel(t, srv)
+	m = drive(m, m.Init())
+	path := filepath.Join(t.TempDir(), "selection.json")
+	m = press(m, runes(" "), runes("e"), tea.KeyMsg{Type: tea.KeyCtrlU})
+	m = press(m, typed(path)...)
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("expected export file: %v (status %q)", err, m.status)
+	}
+	if !strings.Contains(string(data), `"selected": 1`) || !strings.Contains(string(data), `"title": "Selected repos"`) {
+		t.Fatalf("unexpected selection report:\n%s", data)
+	}
+
+	// After a batch, e exports its results instead.
+	m = press(m, runes("d"))
+	m = press(m, typed("me approves")...)
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter}, runes("e"), tea.KeyMsg{Type: tea.KeyCtrlU})
+	path = filepath.Join(t.TempDir(), "batch.md")
+	m = press(m, typed(path)...)
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	data, _ = os.ReadFile(path)
+	if !strings.Contains(string(data), "- deleted: 1") || !strings.Contains(string(data), "- Size freed: 300 KB") {
+		t.Fatalf("unexpected batch report:\n%s", data)
+	}
+}
@@ -0,0 +1,338 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/seeg/github-fork-manager/internal/fuzzy"
+	"github.com/seeg/github-fork-manager/internal/gh"
+)
+
+// Lists longer than liveFilterLimit refilter filterDebounce after the last
+// keystroke instead of on every one.
+const (
+	liveFilterLimit = 2000
+	filterDebounce  = 120 * time.Millisecond
+)
+
+type filterDebounceMsg struct {
+	seq int
+}
+
+// matchPositions holds the rune positions a filter matched in each field,
+// for highlighting.
+type matchPositions struct {
+	name, language, parent []int
+}
+
+// repoIndex holds the fields a filter matches, prepared once per repo so
+// typing a filter stays fast on long lists.
+type repoIndex struct {
+	fields [3]fuzzy.Text
+}
+
+func indexRepo(repo gh.Repo) repoIndex {
+	return repoIndex{fields: [3]fuzzy.Text{
+		fuzzy.Prepare(repo.FullName),
+		fuzzy.Prepare(repo.Language),
+		fuzzy.Prepare(repo.Parent),
+	}}
+}
+
+// reindex prepares every listed repo for filtering.
+func (m *model) reindex() {
+	m.index = make(map[string]repoIndex, len(m.repos))
+	for _, repo := range m.repos {
+		m.index[repo.FullName] = indexRepo(repo)
+	}
+}
+
+// indexOf returns the prepared fields of repo, preparing them on the spot
+// when the repo is not indexed.
+func (m model) indexOf(repo gh.Repo) repoIndex {
+	if ix, ok := m.index[repo.FullName]; ok {
+		return ix
+	}
+	return indexRepo(repo)
+}
+
+// fuzzyMatch scores repo against the lowercase filter terms. Every term
+// must match the full name, language or parent; each scores its best
+// field.
+func fuzzyMatch(ix repoIndex, terms [][]rune) (score int, pos matchPositions, ok bool) {
+	for _, term := range terms {
+		best, field, positions := -1, 0, []int(nil)
+		for i, text := range ix.fields {
+			s, p, ok := text.Match(term)
+			if ok && s > best {
+				best, field, positions = s, i, p
+			}
+		}
+		if best < 0 {
+			return 0, matchPositions{}, false
+		}
+		score += best
+		switch field {
+		case 0:
+			pos.name = append(pos.name, positions...)
+		case 1:
+			pos.language = append(pos.language, positions...)
+		case 2:
+			pos.parent = append(pos.parent, positions...)
+		}
+	}
+	return score, pos, true
+}
+
+// qualifier is a field:value filter term that matches a repo field
+// exactly, e.g. lang:go, is:private, pushed:1-3y, topic:keep or
+// repo:me/tool.
+type qualifier struct {
+	field, value string
+}
+
+// Push-age buckets, as used by pushed: terms and the stats screen.
+const ageUnknown = "unknown"
+
+var ageBuckets = []string{"0-3m", "3-12m", "1-3y", "3y+"}
+
+// isValues are the values an is: term accepts.
+var isValues = []string{"public", "private", "active", "archived", "fork", "source"}
+
+// splitQualifiers separates lang:, is:, pushed:, topic: and repo: terms
+// from the rest of the filter text.
+func splitQualifiers(text string) (string, []qualifier) {
+	if !strings.Contains(text, ":") {
+		return text, nil
+	}
+	var rest []string
+	var quals []qualifier
+	for _, term := range strings.Fields(text) {
+		field, value, _ := strings.Cut(strings.ToLower(term), ":")
+		switch field {
+		case "lang", "is", "pushed", "topic", "repo":
+			quals = append(quals, qualifier{field, value})
+		default:
+			rest = append(rest, term)
+		}
+	}
+	return strings.Join(rest, " "), quals
+}
+
+// matches reports whether repo has the qualified value; now dates pushed:
+// buckets.
+func (q qualifier) matches(repo gh.Repo, now time.Time) bool {
+	switch q.field {
+	case "lang":
+		return langValue(repo.Language) == q.value
+	case "pushed":
+		return ageBucket(repo.PushedAt, now) == q.value
+	case "topic":
+		return contains(repo.Topics, q.value)
+	case "repo":
+		return strings.EqualFold(repo.FullName, q.value)
+	}
+	switch q.value {
+	case "public":
+		return !repo.Private
+	case "private":
+		return repo.Private
+	case "active":
+		return !repo.Archived
+	case "archived":
+		return repo.Archived
+	case "fork":
+		return repo.Fork
+	case "source":
+		return !repo.Fork
+	}
+	return false
+}
+
+// known reports whether q can match at all.
+func (q qualifier) known() bool {
+	switch q.field {
+	case "lang", "topic", "repo":
+		return q.value != ""
+	case "pushed":
+		return q.value == ageUnknown || contains(ageBuckets, q.value)
+	}
+	return contains(isValues, q.value)
+}
+
+// unknownQualifier returns the first lang:, is: or pushed: term of filter
+// that can never match, or "".
+func unknownQualifier(filter string) string {
+	text, _, _ := splitUpstreamTerms(filter)
+	_, quals := splitQualifiers(text)
+	for _, q := range quals {
+		if !q.known() {
+			return q.field + ":" + q.value
+		}
+	}
+	return ""
+}
+
+// langValue is how lang: terms spell a language: lowercase, spaces as
+// dashes, and "none" for repos without one.
+func langValue(language string) string {
+	if language == "" {
+		return "none"
+	}
+	return strings.ReplaceAll(strings.ToLower(language), " ", "-")
+}
+
+// ageBucket sorts a push date into one of ageBuckets.
+func ageBucket(pushed, now time.Time) string {
+	switch {
+	case pushed.IsZero():
+		return ageUnknown
+	case pushed.After(now.AddDate(0, -3, 0)):
+		return ageBuckets[0]
+	case pushed.After(now.AddDate(-1, 0, 0)):
+		return ageBuckets[1]
+	case pushed.After(now.AddDate(-3, 0, 0)):
+		return ageBuckets[2]
+	}
+	return ageBuckets[3]
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// filterTerms returns the lowercase fuzzy terms of filter, without its
+// upstream:state and field:value terms.
+func filterTerms(filter string) [][]rune {
+	text, _, _ := splitUpstreamTerms(filter)
+	text, _ = splitQualifiers(text)
+	var terms [][]rune
+	for _, field := range strings.Fields(strings.ToLower(text)) {
+		terms = append(terms, []rune(field))
+	}
+	return terms
+}
+
+// filterMatch compiles filter into the test the list and select-where
+// share: upstream:, lang:, is:, pushed: and topic: terms must all hold and
+// every other term must fuzzy-match a field. It also returns the score.
+func (m model) filterMatch(filter string) func(gh.Repo) (int, bool) {
+	text, states, _ := splitUpstreamTerms(filter)
+	_, quals := splitQualifiers(text)
+	terms := filterTerms(filter)
+	now := time.Now()
+	return func(repo gh.Repo) (int, bool) {
+		if len(states) > 0 && !m.matchesUpstream(repo, states) {
+			return 0, false
+		}
+		if !matchesAll(repo, quals, now) {
+			return 0, false
+		}
+		score, _, ok := fuzzyMatch(m.indexOf(repo), terms)
+		return score, ok
+	}
+}
+
+// rankRepos keeps the repos matching filter, best fuzzy score first. Ties
+// keep their order, so an empty filter leaves the list as it was.
+func (m model) rankRepos(repos []gh.Repo, filter string) []gh.Repo {
+	match := m.filterMatch(filter)
+	type scored struct {
+		repo  gh.Repo
+		score int
+	}
+	var hits []scored
+	for _, repo := range repos {
+		score, ok := match(repo)
+		if !ok {
+			continue
+		}
+		hits = append(hits, scored{repo, score})
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].score > hits[j].score })
+	out := make([]gh.Repo, len(hits))
+	for i, h := range hits {
+		out[i] = h.repo
+	}
+	return out
+}
+
+func matchesAll(repo gh.Repo, quals []qualifier, now time.Time) bool {
+	for _, q := range quals {
+		if !q.matches(repo, now) {
+			return false
+		}
+	}
+	return true
+}
+
+// highlights returns where the current filter matches repo.
+func (m model) highlights(repo gh.Repo) matchPositions {
+	terms := filterTerms(m.filterInput.Value())
+	if len(terms) == 0 {
+		return matchPositions{}
+	}
+	_, pos, _ := fuzzyMatch(m.indexOf(repo), terms)
+	return pos
+}
+
+// liveFilter refilters while the filter is typed, right away for short
+// lists and debounced for long ones.
+func (m *model) liveFilter() tea.Cmd {
+	m.filterSeq++
+	if len(m.repos) > liveFilterLimit {
+		seq := m.filterSeq
+		return tea.Tick(filterDebounce, func(time.Time) tea.Msg { return filterDebounceMsg{seq: seq} })
+	}
+	m.refilterLive()
+	return nil
+}
+
+func (m *model) refilterLive() {
+	m.refilterKeepingCursor()
+}
+
+// refilterKeepingCursor reapplies the filter and keeps the cursor on the
+// same repo while it still matches, or moves it to the top.
+func (m *model) refilterKeepingCursor() {
+	current, ok := m.cursorRepo()
+	m.setFiltered(m.applyFilter(m.filterInput.Value()))
+	m.cursor = 0
+	if ok {
+		for i, row := range m.rows {
+			if !row.header && row.repo.FullName == current.FullName {
+				m.cursor = i
+				break
+			}
+		}
+	}
+	m.ensureVisible()
+}
+
+// highlight renders the runes of text at positions in the match style.
+func (t theme) highlight(text string, positions []int) string {
+	if len(positions) == 0 {
+		return text
+	}
+	at := make(map[int]bool, len(positions))
+	for _, p := range positions {
+		at[p] = true
+	}
+	var b strings.Builder
+	for i, r := range []rune(text) {
+		if at[i] {
+			b.WriteString(t.match.Render(string(r)))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/seeg/github-fork-manager/internal/gh"
+	"github.com/seeg/github-fork-manager/internal/ghfake"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata/")
+
+// ansi matches CSI sequences (colours, cursor) and OSC 8 hyperlinks.
+var ansi = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]|\x1b\]8;;[^\x1b]*\x1b\\`)
+
+// step is one scripted interaction: the keys are pressed in order and the
+// resulting view is appended to the snapshot under name.
+type step struct {
+	name string
+	keys []tea.KeyMsg
+}
+
+func keys(k ...tea.KeyMsg) []tea.KeyMsg { return k }
+
+var (
+	keyEnter = tea.KeyMsg{Type: tea.KeyEnter}
+	keyEsc   = tea.KeyMsg{Type: tea.KeyEsc}
+	keySpace = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	keyDown  = tea.KeyMsg{Type: tea.KeyDown}
+)
+
+// flowServer seeds a small, fixed set of forks so views are stable.
+func flowServer() *ghfake.Server {
+	srv := ghfake.New("me")
+	pushed := func(day int) time.Time { return time.Date(2024, 3, day, 12, 0, 0, 0, time.UTC) }
+	srv.AddRepo(ghfake.Repo{Owner: "up", Name: "lib"})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "go-tool", Parent: "up/lib", Language: "Go", PushedAt: pushed(5)})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "py-script", Parent: "up/lib", Language: "Python", Private: true, PushedAt: pushed(4)})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "old-go", Parent: "up/lib", Language: "Go", Archived: true, PushedAt: pushed(3)})
+	return srv
+}
+
+// runFlow loads m, plays the steps and returns the concatenated,
+// ANSI-stripped views.
+func runFlow(m model, steps []step) string {
+	m = drive(m, m.Init())
+	var b strings.Builder
+	b.WriteString("### loaded\n")
+	b.WriteString(normalizeView(m.View()))
+	for _, s := range steps {
+		m = press(m, s.keys...)
+		b.WriteString("\n### " + s.name + "\n")
+		b.WriteString(normalizeView(m.View()))
+	}
+	return b.String()
+}
+
+func normalizeView(v string) string {
+	v = ansi.ReplaceAllString(v, "")
+	lines := strings.Split(v, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("write golden: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden (run go test -update): %v", err)
+	}
+	if string(want) != got {
+		t.Fatalf("view mismatch for %s (run go test -update to accept)\n--- want\n%s\n--- got\n%s", name, want, got)
+	}
+}
+
+func TestFlowSelectAll(t *testing.T) {
+	m := newFakeModel(t, flowServer())
+	got := runFlow(m, []step{
+		{"select all", keys(runes("a"))},
+		{"toggle off cursor row", keys(keyDown, keySpace)},
+		{"select all again", keys(runes("a"))},
+		{"clear all", keys(runes("a"))},
+	})
+	assertGolden(t, "select_all", got)
+}
+
+func TestFlowFiltering(t *testing.T) {
+	m := newFakeModel(t, flowServer())
+	got := runFlow(m, []step{
+		{"typing filter", append(keys(runes("/")), typed("go")...)},
+		{"filter applied", keys(keyEnter)},
+		{"select visible", keys(runes("a"))},
+		{"edit narrows live", append(keys(runes("/")), typed("-t")...)},
+		{"esc restores filter", keys(keyEsc)},
+		{"filter cleared", keys(runes("/"), tea.KeyMsg{Type: tea.KeyCtrlU}, keyEnter)},
+	})
+	assertGolden(t, "filtering", got)
+}
+
+func TestFlowWrongConfirmation(t *testing.T) {
+	m := newFakeModel(t, flowServer())
+	got := runFlow(m, []step{
+		{"confirm prompt", keys(keySpace, runes("d"))},
+		{"wrong phrase", append(typed("yes"), keyEnter)},
+		{"cancelled", keys(keyEsc)},
+	})
+	assertGolden(t, "wrong_confirmation", got)
+}
+
+func TestFlowPartialDeleteFailure(t *testing.T) {
+	srv := flowServer()
+	srv.Fail(ghfake.Failure{Method: http.MethodDelete, Path: "/repos/me/py-script", Status: http.StatusForbidden, Message: "Must have admin rights to Repository."})
+	m := newFakeModel(t, srv)
+	got := runFlow(m, []step{
+		{"confirm prompt", keys(runes("a"), runes("d"))},
+		{"deleted", append(typed("me approves"), keyEnter)},
+	})
+	assertGolden(t, "partial_delete_failure", got)
+}
+
+func TestFlowOpenPullRequestsNeedForce(t *testing.T) {
+	srv := flowServer()
+	srv.AddPullRequest(ghfake.PullRequest{Base: "up/lib", Author: "me", Head: "me/go-tool", Title: "Add retries"})
+	m := newFakeModel(t, srv)
+	got := runFlow(m, []step{
+		{"select PR fork only", keys(keySpace)},
+		{"delete refused", keys(runes("d"))},
+		{"force delete prompt", keys(runes("D"))},
+		{"second acknowledgment", append(typed("me approves"), keyEnter)},
+		{"deleted", append(typed("close 1 pull requests"), keyEnter)},
+	})
+	assertGolden(t, "open_pull_requests", got)
+	if prs := srv.PullRequests(); !prs[0].Closed {
+		t.Fatalf("expected forced delete to close the pull request")
+	}
+}
+
+func TestFlowUniqueWorkNeedsAcknowledgment(t *testing.T) {
+	srv := flowServer()
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "patched", Parent: "up/lib", Language: "Go",
+		PushedAt: time.Date(2024, 3, 6, 12, 0, 0, 0, time.UTC),
+		Branches: map[string]string{"main": "sha-up-lib", "hotfix": "f00d"}})
+	m := newFakeModel(t, srv)
+	got := runFlow(m, []step{
+		{"confirm prompt", keys(keySpace, keyDown, keySpace, runes("d"))},
+		{"unique work listed", append(typed("me approves"), keyEnter)},
+		{"deleted", append(typed("delete unique work"), keyEnter)},
+	})
+	assertGolden(t, "unique_work", got)
+}
+
+func TestFlowDryRunRehearsesDelete(t *testing.T) {
+	srv := flowServer()
+	m := newFakeModel(t, srv)
+	m.dryRun = true
+	m.client = gh.DryRun(m.client)
+	got := runFlow(m, []step{
+		{"confirm prompt", keys(keySpace, runes("d"))},
+		{"rehearsed", append(typed("me approves"), keyEnter)},
+	})
+	assertGolden(t, "dry_run", got)
+	if _, ok := srv.Repo("me/go-tool"); !ok {
+		t.Fatalf("dry run must not delete on the server")
+	}
+}
+
+func TestFlowVisualRangeAndInvert(t *testing.T) {
+	m := newFakeModel(t, flowServer())
+	got := runFlow(m, []step{
+		{"visual start", keys(runes("v"))},
+		{"visual extended", keys(keyDown)},
+		{"visual finished", keys(runes("v"))},
+		{"inverted", keys(runes("i"))},
+		{"filter hides selection", append(append(keys(runes("/")), typed("py")...), keyEnter)},
+		{"select where", append(append(keys(runes("w"), tea.KeyMsg{Type: tea.KeyCtrlU}), typed("go-tool")...), keyEnter)},
+		{"clear all", keys(runes("x"))},
+	})
+	assertGolden(t, "visual_invert", got)
+}
+
+func TestFlowHelpOverlay(t *testing.T) {
+	m := newFakeModel(t, flowServer())
+	got := runFlow(m, []step{
+		{"help", keys(runes("?"))},
+		{"closed", keys(keyEsc)},
+	})
+	assertGolden(t, "help", got)
+}
+
+func TestFlowGroupByParent(t *testing.T) {
+	srv := flowServer()
+	pushed := func(day int) time.Time { return time.Date(2024, 3, day, 12, 0, 0, 0, time.UTC) }
+	srv.AddRepo(ghfake.Repo{Owner: "old", Name: "dead", Archived: true})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "dead-fork", Parent: "old/dead", Language: "C", PushedAt: pushed(2)})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "orphan", Parent: "ghost/gone", PushedAt: pushed(1)})
+	m := newFakeModel(t, srv)
+	got := runFlow(m, []step{
+		{"grouped", keys(runes("g"))},
+		{"group selected", keys(keySpace)},
+		{"collapsed", keys(runes("z"))},
+		{"header selects group", keys(keySpace)},
+		{"flat again", keys(runes("g"))},
+	})
+	assertGolden(t, "group_by_parent", got)
+}
+
+func TestFlowUpstreamScanAndFilter(t *testing.T) {
+	srv := flowServer()
+	pushed := func(day int) time.Time { return time.Date(2024, 3, day, 12, 0, 0, 0, time.UTC) }
+	srv.AddRepo(ghfake.Repo{Owner: "old", Name: "dead", Archived: true})
+	parent := srv.AddRepo(ghfake.Repo{Owner: "old", Name: "moved"})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "dead-fork", Parent: "old/dead", PushedAt: pushed(2)})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "orphan", Parent: "ghost/gone", PushedAt: pushed(1)})
+	fork := srv.AddRepo(ghfake.Repo{Owner: "me", Name: "moved", Parent: "old/moved", PushedAt: pushed(0)})
+	srv.Move("old/moved", "neworg/moved")
+	m := newFakeModel(t, srv)
+	// An earlier scan recorded the parent under its old name.
+	m.cfg.LogPath = filepath.Join(t.TempDir(), "actions.log")
+	saveKnownParents(m.cfg, map[int64]knownParent{fork.ID: {ID: parent.ID, FullName: "old/moved"}})
+	got := runFlow(m, []step{
+		{"filter scans first", append(append(keys(runes("/")), typed("upstream:deleted,archived")...), keyEnter)},
+		{"transferred only", append(append(keys(runes("/"), tea.KeyMsg{Type: tea.KeyCtrlU}), typed("upstream:transferred")...), keyEnter)},
+		{"unknown state", append(append(keys(runes("/"), tea.KeyMsg{Type: tea.KeyCtrlU}), typed("upstream:gone")...), keyEnter)},
+	})
+	assertGolden(t, "upstream_scan", got)
+	if known := loadKnownParents(m.cfg)[fork.ID]; known.FullName != "neworg/moved" || known.ID != parent.ID {
+		t.Fatalf("expected the new parent name to be recorded, got %+v", known)
+	}
+}
+
+func TestFlowResponsiveColumns(t *testing.T) {
+	srv := flowServer()
+	pushed := time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC)
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "a-very-long-repository-name-that-will-not-fit", Parent: "some-organisation/a-very-long-repository-name-that-will-not-fit", Language: "TypeScript", Size: 52_000, PushedAt: pushed})
+	m := newFakeModel(t, srv)
+	m = drive(m, m.Init())
+	var b strings.Builder
+	for _, width := range []int{160, 110, 72, 40} {
+		next, _ := m.Update(tea.WindowSizeMsg{Width: width, Height: 30})
+		m = next.(model)
+		b.WriteString(fmt.Sprintf("### width %d\n", width))
+		b.WriteString(normalizeView(m.View()))
+		b.WriteString("\n")
+	}
+	assertGolden(t, "responsive_columns", b.String())
+}
+
+func TestFlowVisibilitySkipsPublicForks(t *testing.T) {
+	m := newFakeModel(t, flowServer())
+	m.cfg.LogPath = filepath.Join(t.TempDir(), "actions.log")
+	got := runFlow(m, []step{
+		{"only public forks to make private", keys(runes("a"), runes("p"))},
+		{"make public", append(append(keys(runes("o")), typed("me approves")...), keyEnter)},
+	})
+	assertGolden(t, "visibility_forks", got)
+}
@@ -0,0 +1,353 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/seeg/github-fork-manager/internal/gh"
+)
+
+// noParent groups forks without a parent: detached forks whose upstream
+// was deleted, and on GitHub, whose listings carry no parents, forks whose
+// upstream has not been scanned yet.
+const noParent = "(no parent)"
+
+// listRow is one line of the repo list: a repo, or in the grouped view a
+// parent header.
+type listRow struct {
+	header bool
+	group  *repoGroup
+	repo   gh.Repo
+}
+
+// repoGroup is the set of filtered forks sharing a parent.
+type repoGroup struct {
+	parent string
+	repos  []gh.Repo
+	newest time.Time
+}
+
+// parentStatus is what a lookup of a group's parent found.
+type parentStatus struct {
+	archived bool
+	missing  bool
+	err      error
+}
+
+type parentCheckedMsg struct {
+	parent string
+	status parentStatus
+}
+
+// buildRows lays out the filtered repos: one row per repo, or in the
+// grouped view a header per parent followed by its repos unless the group
+// is collapsed.
+func (m model) buildRows() []listRow {
+	if !m.grouped {
+		rows := make([]listRow, len(m.filtered))
+		for i, repo := range m.filtered {
+			rows[i] = listRow{repo: repo}
+		}
+		return rows
+	}
+	var rows []listRow
+	for _, g := range groupByParent(m.filtered) {
+		rows = append(rows, listRow{header: true, group: g})
+		if m.collapsed[g.parent] {
+			continue
+		}
+		for _, repo := range g.repos {
+			rows = append(rows, listRow{group: g, repo: repo})
+		}
+	}
+	return rows
+}
+
+// groupByParent groups repos by parent, ordered by parent name with
+// parentless forks last. Repos keep their order within a group.
+func groupByParent(repos []gh.Repo) []*repoGroup {
+	byParent := make(map[string]*repoGroup)
+	var groups []*repoGroup
+	for _, repo := range repos {
+		parent := repo.Parent
+		if parent == "" {
+			parent = noParent
+		}
+		g, ok := byParent[parent]
+		if !ok {
+			g = &repoGroup{parent: parent}
+			byParent[parent] = g
+			groups = append(groups, g)
+		}
+		g.repos = append(g.repos, repo)
+		if repo.PushedAt.After(g.newest) {
+			g.newest = repo.PushedAt
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if (groups[i].parent == noParent) != (groups[j].parent == noParent) {
+			return groups[j].parent == noParent
+		}
+		return strings.ToLower(groups[i].parent) < strings.ToLower(groups[j].parent)
+	})
+	return groups
+}
+
+// setFiltered replaces the filtered repos and rebuilds the rows.
+func (m *model) setFiltered(repos []gh.Repo) {
+	m.filtered = repos
+	m.rows = m.buildRows()
+}
+
+// cursorRepo is the repo under the cursor; false on headers and empty lists.
+func (m model) cursorRepo() (gh.Repo, bool) {
+	if m.cursor < 0 || m.cursor >= len(m.rows) || m.rows[m.cursor].header {
+		return gh.Repo{}, false
+	}
+	return m.rows[m.cursor].repo, true
+}
+
+// relayout rebuilds the rows after a grouping change and keeps the cursor
+// on the same repo, or on its group header if the group was collapsed.
+func (m *model) relayout() {
+	var name, parent string
+	if m.cursor < len(m.rows) {
+		row := m.rows[m.cursor]
+		name = row.repo.FullName
+		if row.group != nil {
+			parent = row.group.parent
+		}
+		if row.header {
+			name = ""
+		}
+	}
+	m.rows = m.buildRows()
+	m.cursor = 0
+	for i, row := range m.rows {
+		if name != "" && !row.header && row.repo.FullName == name {
+			m.cursor = i
+			break
+		}
+		if row.header && row.group.parent == parent {
+			m.cursor = i
+			if name == "" {
+				break
+			}
+		}
+	}
+	m.ensureVisible()
+}
+
+// toggleGrouped switches between the flat and the grouped list and starts
+// checking the parents in the grouped one.
+func (m *model) toggleGrouped() tea.Cmd {
+	m.grouped = !m.grouped
+	m.relayout()
+	if !m.grouped {
+		m.status = "Flat list"
+		return nil
+	}
+	groups := 0
+	for _, row := range m.rows {
+		if row.header {
+			groups++
+		}
+	}
+	m.status = fmt.Sprintf("Grouped %d repos under %d parents", len(m.filtered), groups)
+	return m.startParentCheck()
+}
+
+// toggleCollapsed folds or unfolds the group under the cursor.
+func (m *model) toggleCollapsed() {
+	if !m.grouped || m.cursor >= len(m.rows) {
+		return
+	}
+	parent := m.rows[m.cursor].group.parent
+	if m.collapsed == nil {
+		m.collapsed = make(map[string]bool)
+	}
+	m.collapsed[parent] = !m.collapsed[parent]
+	m.rows = m.buildRows()
+	for i, row := range m.rows {
+		if row.header && row.group.parent == parent {
+			m.cursor = i
+			break
+		}
+	}
+	m.ensureVisible()
+}
+
+// toggleGroupSelection selects every repo of g, or unselects them all when
+// they already are.
+func (m *model) toggleGroupSelection(g *repoGroup) {
+	if m.groupSelected(g) == len(g.repos) {
+		for _, repo := range g.repos {
+			delete(m.selected, repo.FullName)
+		}
+		m.status = fmt.Sprintf("Unselected %d forks of %s", len(g.repos), g.parent)
+		return
+	}
+	for _, repo := range g.repos {
+		m.selected[repo.FullName] = true
+	}
+	m.status = fmt.Sprintf("Selected %d forks of %s", len(g.repos), g.parent)
+}
+
+func (m model) groupSelected(g *repoGroup) int {
+	n := 0
+	for _, repo := range g.repos {
+		if m.selected[repo.FullName] {
+			n++
+		}
+	}
+	return n
+}
+
+// startParentCheck looks up each group's parent once so dead and archived
+// upstreams can be highlighted. Providers with upstream scans resolve the
+// parents through the scan instead, which looks up each fork once and
+// already reports the parent's state; their listings carry no parents.
+// Forks a refresh brought in are scanned as they show up.
+func (m *model) startParentCheck() tea.Cmd {
+	if scanner, ok := gh.Lookup[gh.UpstreamScanner](m.client); ok {
+		if len(m.upstreamQueue) > 0 {
+			return nil
+		}
+		return m.queueUpstreamScan(scanner, true)
+	}
+	if m.parentChecking {
+		return nil
+	}
+	seen := make(map[string]bool)
+	m.parentQueue = nil
+	for _, repo := range m.repos {
+		if repo.Parent == "" || seen[repo.Parent] {
+			continue
+		}
+		seen[repo.Parent] = true
+		if _, done := m.parents[repo.Parent]; !done {
+			m.parentQueue = append(m.parentQueue, repo.Parent)
+		}
+	}
+	if len(m.parentQueue) == 0 {
+		return nil
+	}
+	m.parentChecking = true
+	return checkParentCmd(m.client, m.parentQueue[0])
+}
+
+// recordParent stores a parent lookup and checks the next one.
+func (m *model) recordParent(msg parentCheckedMsg) tea.Cmd {
+	if m.parents == nil {
+		m.parents = make(map[string]parentStatus)
+	}
+	m.parents[msg.parent] = msg.status
+	if len(m.parentQueue) > 0 {
+		m.parentQueue = m.parentQueue[1:]
+	}
+	if len(m.parentQueue) == 0 {
+		m.parentChecking = false
+		return nil
+	}
+	return checkParentCmd(m.client, m.parentQueue[0])
+}
+
+func checkParentCmd(client gh.Provider, parent string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+		defer cancel()
+		repo, err := client.GetRepo(ctx, parent)
+		switch {
+		case errors.Is(err, gh.ErrNotFound):
+			return parentCheckedMsg{parent: parent, status: parentStatus{missing: true}}
+		case err != nil:
+			return parentCheckedMsg{parent: parent, status: parentStatus{err: err}}
+		}
+		return parentCheckedMsg{parent: parent, status: parentStatus{archived: repo.Archived}}
+	}
+}
+
+// parentProblem describes why a group's parent makes its forks cleanup
+// candidates, or "" when the parent looks healthy or is not checked yet.
+// Upstream scans of the group's forks count as well.
+func (m model) parentProblem(g *repoGroup) string {
+	if g.parent == noParent {
+		if m.parentUnresolved(g) {
+			return ""
+		}
+		return "parent gone"
+	}
+	status := m.parents[g.parent]
+	for _, repo := range g.repos {
+		u := m.upstream[repo.FullName]
+		status.missing = status.missing || u.Has(gh.UpstreamDeleted)
+		status.archived = status.archived || u.Has(gh.UpstreamArchived)
+	}
+	switch {
+	case status.missing:
+		return "parent gone"
+	case status.archived:
+		return "parent archived"
+	}
+	return ""
+}
+
+// parentUnresolved reports whether g holds forks whose parent is simply
+// not known yet. Providers with upstream scans list no parents, so only a
+// scan that found the parent deleted makes a parentless fork detached.
+func (m model) parentUnresolved(g *repoGroup) bool {
+	if g.parent != noParent {
+		return false
+	}
+	if _, ok := gh.Lookup[gh.UpstreamScanner](m.client); !ok {
+		return false
+	}
+	for _, repo := range g.repos {
+		if !m.upstream[repo.FullName].Has(gh.UpstreamDeleted) {
+			return true
+		}
+	}
+	return false
+}
+
+// groupHeader renders a group row as "▾ parent — 3 forks · 1 selected ·
+// newest push 2024-03-05".
+func (m model) groupHeader(g *repoGroup) string {
+	fold := "▾"
+	if m.collapsed[g.parent] {
+		fold = "▸"
+	}
+	check := "[ ]"
+	switch n := m.groupSelected(g); {
+	case n == len(g.repos):
+		check = m.theme.selected.Render("[x]")
+	case n > 0:
+		check = m.theme.selected.Render("[-]")
+	}
+	parts := []string{fmt.Sprintf("%d forks", len(g.repos))}
+	if len(g.repos) == 1 {
+		parts[0] = "1 fork"
+	}
+	if n := m.groupSelected(g); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d selected", n))
+	}
+	if g.newest.IsZero() {
+		parts = append(parts, "newest push unknown")
+	} else {
+		parts = append(parts, "newest push "+g.newest.Format("2006-01-02"))
+	}
+	name := m.theme.title.Render(g.parent)
+	switch problem := m.parentProblem(g); {
+	case problem != "":
+		name = m.theme.errorText.Render(g.parent + " (" + problem + ")")
+	case m.parentUnresolved(g):
+		name = m.theme.muted.Render(g.parent + " (parent unknown)")
+	}
+	return fmt.Sprintf("%s %s %s — %s", check, fold, name, strings.Join(parts, " · "))
+}
@@ -0,0 +1,620 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/seeg/github-fork-manager/internal/config"
+	"github.com/seeg/github-fork-manager/internal/gh"
+	"github.com/seeg/github-fork-manager/internal/ghfake"
+)
+
+// newFakeModel returns a model wired to srv with a static cursor, so that
+// no blink commands end up in the command stream. srv is started on first
+// use; later calls reuse it, e.g. to simulate a second session.
+func newFakeModel(t *testing.T, srv *ghfake.Server) model {
+	t.Helper()
+	url := srv.URL()
+	if url == "" {
+		url = srv.Start()
+		t.Cleanup(srv.Close)
+	}
+	cfg := config.Config{Provider: config.ProviderGitHub, APIBase: url, Token: "token"}
+	m := newModel(cfg, gh.New(url, "token"), true)
+	m.filterInput.Cursor.SetMode(cursor.CursorStatic)
+	m.confirmInput.Cursor.SetMode(cursor.CursorStatic)
+	m.whereInput.Cursor.SetMode(cursor.CursorStatic)
+	m.nameInput.Cursor.SetMode(cursor.CursorStatic)
+	return m
+}
+
+// drive runs cmd and feeds every resulting message back into m until no
+// commands are left, the way the Bubble Tea runtime would.
+func drive(m model, cmd tea.Cmd) model {
+	if cmd == nil {
+		return m
+	}
+	msg := cmd()
+	switch msg := msg.(type) {
+	case nil, tea.QuitMsg:
+		return m
+	case tea.BatchMsg:
+		for _, c := range msg {
+			m = drive(m, c)
+		}
+		return m
+	}
+	next, cmd := m.Update(msg)
+	return drive(next.(model), cmd)
+}
+
+func press(m model, keys ...tea.KeyMsg) model {
+	for _, k := range keys {
+		next, cmd := m.Update(k)
+		m = drive(next.(model), cmd)
+	}
+	return m
+}
+
+func runes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func typed(s string) []tea.KeyMsg {
+	var out []tea.KeyMsg
+	for _, r := range s {
+		out = append(out, runes(string(r)))
+	}
+	return out
+}
+
+func TestListSelectDeleteAgainstFake(t *testing.T) {
+	srv := ghfake.New("me")
+	srv.AddRepo(ghfake.Repo{Owner: "up", Name: "lib"})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "one", Parent: "up/lib"})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "two", Parent: "up/lib"})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "three", Parent: "up/lib"})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "mine"})
+	srv.Fail(ghfake.Failure{Method: http.MethodDelete, Path: "/repos/me/two", Status: http.StatusForbidden})
+
+	m := newFakeModel(t, srv)
+	m = drive(m, m.Init())
+	if len(m.repos) != 3 || m.userLogin != "me" {
+		t.Fatalf("expected 3 forks for me, got %d for %q", len(m.repos), m.userLogin)
+	}
+
+	m = press(m, runes("a"), runes("d"))
+	if m.mode != modeConfirm || len(m.queue) != 3 {
+		t.Fatalf("expected confirm mode with 3 queued, got mode %v queue %d", m.mode, len(m.queue))
+	}
+	m = press(m, typed("me approves")...)
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+
+	if m.running || len(m.queue) != 0 {
+		t.Fatalf("expected queue to drain, running=%v queue=%d", m.running, len(m.queue))
+	}
+	if m.results["me/one"] != "deleted" || m.results["me/three"] != "deleted" {
+		t.Fatalf("unexpected results: %#v", m.results)
+	}
+	if m.results["me/two"] == "deleted" {
+		t.Fatalf("expected me/two to fail, got %#v", m.results)
+	}
+	if len(m.repos) != 1 || m.repos[0].FullName != "me/two" || !m.selected["me/two"] {
+		t.Fatalf("expected only failed repo to remain selected, got %#v", m.repos)
+	}
+	if _, ok := srv.Repo("me/one"); ok {
+		t.Fatalf("expected me/one to be gone from the server")
+	}
+}
+
+func TestGraceWindowDefersAndCancels(t *testing.T) {
+	srv := ghfake.New("me")
+	srv.AddRepo(ghfake.Repo{Owner: "up", Name: "lib"})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "one", Parent: "up/lib"})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "two", Parent: "up/lib"})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "three", Parent: "up/lib"})
+
+	m := newFakeModel(t, srv)
+	m.cfg.Grace = time.Minute
+	m.cfg.LogPath = filepath.Join(t.TempDir(), "actions.log")
+	m = drive(m, m.Init())
+	m = press(m, runes("a"), runes("d"))
+	m = press(m, typed("me approves")...)
+
+	// Enter schedules a tick; don't drive it or the test waits a minute.
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(model)
+	if !m.graceActive || m.running || len(m.queue) != 3 {
+		t.Fatalf("expected deletes to wait, active=%v running=%v queue=%d", m.graceActive, m.running, len(m.queue))
+	}
+
+	// Cancel the highlighted repo only.
+	cancelled := m.filtered[m.cursor].FullName
+	m = press(m, runes("c"))
+	if len(m.queue) != 2 || m.results[cancelled] != "cancelled before execution" {
+		t.Fatalf("expected %s cancelled, got queue %d results %#v", cancelled, len(m.queue), m.results)
+	}
+
+	// A tick before the deadline keeps waiting; the one at it starts work.
+	next, cmd := m.Update(graceTickMsg(m.graceUntil.Add(-time.Second)))
+	if m = next.(model); !m.graceActive || cmd == nil {
+		t.Fatalf("expected to keep waiting")
+	}
+	next, cmd = m.Update(graceTickMsg(m.graceUntil))
+	m = drive(next.(model), cmd)
+	if m.graceActive || m.running || len(m.repos) != 1 || m.repos[0].FullName != cancelled {
+		t.Fatalf("expected only %s to survive, got %#v", cancelled, m.repos)
+	}
+	if _, ok := srv.Repo(cancelled); !ok {
+		t.Fatalf("cancelled repo was deleted on the server")
+	}
+}
+
+func TestQuitDuringGraceCancelsEverything(t *testing.T) {
+	srv := ghfake.New("me")
+	srv.AddRepo(ghfake.Repo{Owner: "up", Name: "lib"})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "one", Parent: "up/lib"})
+
+	m := newFakeModel(t, srv)
+	m.cfg.Grace = time.Minute
+	m.cfg.LogPath = filepath.Join(t.TempDir(), "actions.log")
+	m = drive(m, m.Init())
+	m = press(m, runes("a"), runes("d"))
+	m = press(m, typed("me approves")...)
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	next, cmd := next.(model).Update(runes("q"))
+	m = next.(model)
+
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Fatalf("expected quit")
+	}
+	if len(m.queue) != 0 || m.graceActive {
+		t.Fatalf("expected queue cleared on quit")
+	}
+	logData, _ := os.ReadFile(m.cfg.LogPath)
+	if !strings.Contains(string(logData), "delete me/one -> cancelled before execution") {
+		t.Fatalf("expected cancellation in log, got %q", logData)
+	}
+	if _, ok := srv.Repo("me/one"); !ok {
+		t.Fatalf("repo deleted despite quitting during grace window")
+	}
+}
+
+func TestQuarantineThenPurgeView(t *testing.T) {
+	srv := ghfake.New("me")
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "old", Topics: []string{"quarantined", "quarantined-2020-01-01"}})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "app"})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "keep"})
+
+	m := newFakeModel(t, srv)
+	m.showForks = false
+	m.cfg.QuarantinePrefix = "zz-retired-"
+	m.cfg.PurgeAfterDays = 30
+	m.cfg.LogPath = filepath.Join(t.TempDir(), "actions.log")
+	m = drive(m, m.Init())
+
+	for m.filtered[m.cursor].FullName != "me/app" {
+		m = press(m, runes("j"))
+	}
+	m = press(m, runes(" "), runes("Q"))
+	if m.mode != modeConfirm || m.action != actionQuarantine || len(m.queue) != 1 {
+		t.Fatalf("expected quarantine confirmation, got mode %v action %v queue %d", m.mode, m.action, len(m.queue))
+	}
+	m = press(m, typed("me approves")...)
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+
+	if m.results["me/app"] != "quarantined as me/zz-retired-app" || len(m.selected) != 0 {
+		t.Fatalf("unexpected results: %#v", m.results)
+	}
+	r, ok := srv.Repo("me/zz-retired-app")
+	if !ok || !r.Private || len(r.Topics) != 2 {
+		t.Fatalf("unexpected server state: %#v", r)
+	}
+	logData, _ := os.ReadFile(m.cfg.LogPath)
+	if !strings.Contains(string(logData), "quarantine me/app -> quarantined as me/zz-retired-app") {
+		t.Fatalf("expected quarantine in log, got %q", logData)
+	}
+
+	// Only the repo quarantined long ago is due for purging.
+	m = press(m, runes("P"))
+	if len(m.filtered) != 1 || m.filtered[0].FullName != "me/old" {
+		t.Fatalf("expected only me/old in purge view, got %#v", m.filtered)
+	}
+	m = press(m, runes("P"))
+	if len(m.filtered) != 3 {
+		t.Fatalf("expected all repos after leaving purge view, got %d", len(m.filtered))
+	}
+}
+
+func TestQuarantineRefusedForForks(t *testing.T) {
+	srv := ghfake.New("me")
+	srv.AddRepo(ghfake.Repo{Owner: "up", Name: "lib"})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "one", Parent: "up/lib"})
+
+	m := newFakeModel(t, srv)
+	m = drive(m, m.Init())
+	m = press(m, runes("a"), runes("Q"))
+	if m.mode == modeConfirm || len(m.queue) != 0 {
+		t.Fatalf("expected quarantine to be refused in fork view")
+	}
+}
+
+func TestSavedSelectionSurvivesSessions(t *testing.T) {
+	srv := ghfake.New("me")
+	srv.AddRepo(ghfake.Repo{Owner: "up", Name: "lib"})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "one", Parent: "up/lib", Language: "Go"})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "two", Parent: "up/lib", Language: "Go"})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "three", Parent: "up/lib"})
+	dir := filepath.Join(t.TempDir(), "selections")
+
+	m := newFakeModel(t, srv)
+	m.cfg.SelectionsDir = dir
+	m = drive(m, m.Init())
+	m = press(m, runes("/"))
+	m = press(m, typed("go")...)
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter}, runes("a"), runes("s"))
+	m = press(m, typed("review")...)
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.activeSet != "review" || !strings.HasPrefix(m.status, "Saved 2 selected") {
+		t.Fatalf("expected set saved, got %q", m.status)
+	}
+
+	// A teammate deletes one of the repos before the next session.
+	if err := gh.New(srv.URL(), "token").DeleteRepo(context.Background(), "me/two"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	next := newFakeModel(t, srv)
+	next.cfg.SelectionsDir = dir
+	next = drive(next, next.Init())
+	next = press(next, runes("L"))
+	if next.mode != modeLoadSet || !strings.Contains(next.status, "review") {
+		t.Fatalf("expected load prompt listing sets, got mode %v status %q", next.mode, next.status)
+	}
+	next = press(next, typed("review")...)
+	next = press(next, tea.KeyMsg{Type: tea.KeyEnter})
+	if len(next.selected) != 1 || !next.selected["me/one"] || next.filterInput.Value() != "go" {
+		t.Fatalf("unexpected restored state: %v filter %q", next.selected, next.filterInput.Value())
+	}
+	if !strings.Contains(next.status, "1 selected repos no longer exist: me/two") {
+		t.Fatalf("expected missing repo reported, got %q", next.status)
+	}
+
+	// Selections that vanish in a refresh are reported too.
+	if err := gh.New(srv.URL(), "token").DeleteRepo(context.Background(), "me/one"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	next = press(next, runes("r"))
+	if len(next.selected) != 0 || !strings.Contains(next.status, "no longer exist: me/one") {
+		t.Fatalf("expected refresh to reconcile, got %v %q", next.selected, next.status)
+	}
+}
+
+func TestSelectFromListPreselects(t *testing.T) {
+	srv := ghfake.New("me")
+	srv.AddRepo(ghfake.Repo{Owner: "up", Name: "lib"})
+	srv.AddRepo(ghfake.Repo{ID: 10, Owner: "me", Name: "one", Parent: "up/lib"})
+	srv.AddRepo(ghfake.Repo{ID: 11, Owner: "me", Name: "two", Parent: "up/lib"})
+	srv.AddRepo(ghfake.Repo{ID: 12, Owner: "me", Name: "three", Parent: "up/lib"})
+
+	m := newFakeModel(t, srv)
+	m.pendingImport = &importedList{source: "list.txt", entries: []string{"me/one", "git@github.com:me/two.git", "12", "me/gone"}}
+	m = drive(m, m.Init())
+	if len(m.selected) != 3 {
+		t.Fatalf("expected 3 preselected, got %v", m.selected)
+	}
+	if len(m.unmatched) != 1 || m.unmatched[0] != "me/gone" {
+		t.Fatalf("expected me/gone unmatched, got %v", m.unmatched)
+	}
+	if !strings.Contains(m.status, "Selected 3 repos from list.txt · 1 entries matched nothing: me/gone") {
+		t.Fatalf("unexpected status %q", m.status)
+	}
+}
+
+func TestMouseClicksSelectAndScroll(t *testing.T) {
+	srv := ghfake.New("me")
+	srv.AddRepo(ghfake.Repo{Owner: "up", Name: "lib"})
+	for i := 0; i < 6; i++ {
+		srv.AddRepo(ghfake.Repo{Owner: "me", Name: fmt.Sprintf("fork-%d", i), Parent: "up/lib",
+			PushedAt: time.Date(2024, 3, 10-i, 0, 0, 0, 0, time.UTC)})
+	}
+	m := newFakeModel(t, srv)
+	m = drive(m, m.Init())
+	m.listHeight = 4
+
+	top := strings.Count(m.headerView(), "\n")
+	click := func(m model, x, row int, shift bool) model {
+		next, _ := m.Update(tea.MouseMsg{X: x, Y: top + row, Shift: shift, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress})
+		return next.(model)
+	}
+
+	m = click(m, 12, 1, false)
+	if m.cursor != 1 || len(m.selected) != 0 {
+		t.Fatalf("expected click on name to move cursor only, got cursor %d selected %v", m.cursor, m.selected)
+	}
+	m = click(m, 3, 2, false)
+	if m.cursor != 2 || !m.selected["me/fork-2"] {
+		t.Fatalf("expected checkbox click to select fork-2, got %v", m.selected)
+	}
+	m = click(m, 12, 0, true)
+	if len(m.selected) != 3 || !m.selected["me/fork-0"] || !m.selected["me/fork-1"] {
+		t.Fatalf("expected shift-click to select rows 0-2, got %v", m.selected)
+	}
+	m = click(m, 12, 9, false)
+	if m.cursor != 0 {
+		t.Fatalf("expected click below the list to be ignored, got cursor %d", m.cursor)
+	}
+
+	next, _ := m.Update(tea.MouseMsg{Button: tea.MouseButtonWheelDown, Action: tea.MouseActionPress})
+	m = next.(model)
+	if m.listOffset != 2 || m.cursor != 2 {
+		t.Fatalf("expected wheel to scroll to the end, got offset %d cursor %d", m.listOffset, m.cursor)
+	}
+	m = click(m, 12, 0, false)
+	if m.filtered[m.cursor].FullName != "me/fork-2" {
+		t.Fatalf("expected first visible row after scrolling, got %s", m.filtered[m.cursor].FullName)
+	}
+}
+
+func TestMakePrivateLeavesPublicForksOutOfTheQueue(t *testing.T) {
+	m := newModel(config.Config{}, gh.New("", ""), false)
+	m.repos = []gh.Repo{
+		{FullName: "me/site"},
+		{FullName: "me/fork", Fork: true},
+		{FullName: "me/hidden", Private: true},
+	}
+	m.selected = map[string]bool{"me/site": true, "me/fork": true, "me/hidden": true}
+	m.setFiltered(m.repos)
+	m.beginVisibility(true)
+	if m.mode != modeConfirm || len(m.queue) != 1 || m.queue[0].FullName != "me/site" {
+		t.Fatalf("expected only me/site queued, got mode %v queue %v", m.mode, m.queue)
+	}
+	if !strings.Contains(m.confirmPrompt, "Skipping 1 public fork") || !strings.HasSuffix(m.status, " · 1 skipped") {
+		t.Fatalf("expected the skipped fork reported, got prompt %q status %q", m.confirmPrompt, m.status)
+	}
+}
+
+func TestMakePrivateKeepsFailuresSelected(t *testing.T) {
+	srv := ghfake.New("me")
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "site"})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "docs"})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "secret", Private: true})
+	srv.Fail(ghfake.Failure{Method: http.MethodPatch, Path: "/repos/me/docs", Status: http.StatusForbidden, Message: "Must have admin rights to Repository."})
+
+	m := newFakeModel(t, srv)
+	m.showForks = false
+	m.cfg.LogPath = filepath.Join(t.TempDir(), "actions.log")
+	m = drive(m, m.Init())
+	m = press(m, runes("a"), runes("p"))
+	if m.mode != modeConfirm || m.action != actionMakePrivate || len(m.queue) != 2 {
+		t.Fatalf("expected 2 queued, got mode %v action %v queue %d", m.mode, m.action, len(m.queue))
+	}
+	m = press(m, typed("me approves")...)
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+
+	if m.results["me/site"] != "made private" || !strings.HasPrefix(m.results["me/docs"], "error: ") {
+		t.Fatalf("unexpected results: %#v", m.results)
+	}
+	if r, _ := srv.Repo("me/site"); !r.Private {
+		t.Fatalf("me/site still public on the server")
+	}
+	if m.selected["me/site"] || !m.selected["me/docs"] {
+		t.Fatalf("expected only the failure to stay selected, got %v", m.selected)
+	}
+	logData, _ := os.ReadFile(m.cfg.LogPath)
+	if !strings.Contains(string(logData), "make private me/site -> made private") {
+		t.Fatalf("expected visibility change in log, got %q", logData)
+	}
+}
+
+func TestExportNamesLastBatch(t *testing.T) {
+	srv := ghfake.New("me")
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "secret", Private: true})
+
+	m := newFakeModel(t, srv)
+	m.showForks = false
+	m = drive(m, m.Init())
+	m = press(m, runes("a"), runes("o"))
+	m = press(m, typed("me approves")...)
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.results["me/secret"] != "made public" {
+		t.Fatalf("unexpected results: %#v", m.results)
+	}
+	// Nothing is left to queue, so the prompt must not relabel the batch.
+	m = press(m, runes("p"))
+	if m.mode != modeNormal {
+		t.Fatalf("expected no confirmation, got mode %v", m.mode)
+	}
+
+	path := filepath.Join(t.TempDir(), "report.md")
+	m = press(m, runes("e"), tea.KeyMsg{Type: tea.KeyCtrlU})
+	m = press(m, typed(path)...)
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read export: %v (status %q)", err, m.status)
+	}
+	if !strings.HasPrefix(string(data), "# Make public batch\n") || !strings.Contains(string(data), "| me/secret | made public |") {
+		t.Fatalf("unexpected report:\n%s", data)
+	}
+}
+
+func TestExportAfterTagging(t *testing.T) {
+	srv := ghfake.New("me")
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "secret", Private: true})
+
+	m := newFakeModel(t, srv)
+	m.showForks = false
+	m = drive(m, m.Init())
+	m = press(m, runes("a"), runes("o"))
+	m = press(m, typed("me approves")...)
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	m = press(m, runes("a"), runes("t"))
+	m = press(m, typed("keep")...)
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.results["me/secret"] != "tagged: keep" {
+		t.Fatalf("unexpected results: %#v", m.results)
+	}
+
+	// The tag batch never reaches history, so the export is still the
+	// visibility batch under its own title.
+	path := filepath.Join(t.TempDir(), "report.md")
+	m = press(m, runes("e"), tea.KeyMsg{Type: tea.KeyCtrlU})
+	m = press(m, typed(path)...)
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read export: %v (status %q)", err, m.status)
+	}
+	if !strings.HasPrefix(string(data), "# Make public batch\n") || !strings.Contains(string(data), "| me/secret | made public |") {
+		t.Fatalf("unexpected report:\n%s", data)
+	}
+}
+
+func TestTagSelectionThenFilterByTopic(t *testing.T) {
+	srv := ghfake.New("me")
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "site", Topics: []string{"web"}})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "docs", Topics: []string{"keep"}})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "tools"})
+
+	m := newFakeModel(t, srv)
+	m.showForks = false
+	m.cfg.LogPath = filepath.Join(t.TempDir(), "actions.log")
+	m = drive(m, m.Init())
+	m = press(m, runes("a"), runes("t"))
+	if m.mode != modeAddTopic {
+		t.Fatalf("expected the topic prompt, got mode %v", m.mode)
+	}
+	m = press(m, typed("Keep")...)
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+
+	if m.results["me/site"] != "tagged: keep" || m.results["me/docs"] != "unchanged: already tagged keep" {
+		t.Fatalf("unexpected results: %#v", m.results)
+	}
+	if r, _ := srv.Repo("me/site"); strings.Join(r.Topics, ",") != "web,keep" {
+		t.Fatalf("me/site topics on the server = %v", r.Topics)
+	}
+	if m.status != "Tag keep: 1 unchanged · 2 tagged" {
+		t.Fatalf("status = %q", m.status)
+	}
+	if len(m.selected) != 3 {
+		t.Fatalf("expected the selection to stay, got %v", m.selected)
+	}
+	if _, err := os.Stat(lastBatchPath(m.cfg)); !os.IsNotExist(err) || len(m.history) != 0 {
+		t.Fatalf("tagging must not replace the last batch (stat: %v, history %d)", err, len(m.history))
+	}
+
+	m = press(m, runes("/"))
+	m = press(m, typed("topic:web")...)
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if len(m.filtered) != 1 || m.filtered[0].FullName != "me/site" {
+		t.Fatalf("topic:web listed %v", m.filtered)
+	}
+	if !strings.Contains(m.View(), "topics: web, keep") {
+		t.Fatalf("expected topics in the list meta:\n%s", m.View())
+	}
+
+	m = press(m, runes("T"))
+	if m.status != "Topics on the selection: keep, web" {
+		t.Fatalf("status = %q", m.status)
+	}
+	m = press(m, typed("web")...)
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.results["me/site"] != "untagged: web" || m.results["me/tools"] != "unchanged: no topic web" {
+		t.Fatalf("unexpected results: %#v", m.results)
+	}
+	if r, _ := srv.Repo("me/site"); strings.Join(r.Topics, ",") != "keep" {
+		t.Fatalf("me/site topics on the server = %v", r.Topics)
+	}
+	logData, _ := os.ReadFile(m.cfg.LogPath)
+	if !strings.Contains(string(logData), "untag me/site -> untagged: web") {
+		t.Fatalf("expected untag in log, got %q", logData)
+	}
+}
+
+func TestGroupedViewResolvesParentsBeforeCallingThemGone(t *testing.T) {
+	m := newModel(config.Config{}, gh.New("", ""), false)
+	m.repos = []gh.Repo{
+		{ID: 1, FullName: "me/lib", Fork: true},
+		{ID: 2, FullName: "me/orphan", Fork: true},
+	}
+	m.grouped = true
+	m.setFiltered(m.repos)
+	header := func() string { return m.groupHeader(m.rows[0].group) }
+	if got := header(); !strings.Contains(got, "(no parent) (parent unknown)") {
+		t.Fatalf("expected unscanned parents to be unknown, got %q", got)
+	}
+
+	m.upstreamQueue = append([]gh.Repo{}, m.repos...)
+	m.recordUpstream(upstreamMsg{repo: m.repos[0], result: gh.Upstream{Parent: "up/lib", ParentID: 9}})
+	m.recordUpstream(upstreamMsg{repo: m.repos[1], result: gh.Upstream{States: []string{gh.UpstreamDeleted}}})
+	if len(m.rows) != 4 || m.rows[0].group.parent != "up/lib" || m.rows[2].group.parent != noParent {
+		t.Fatalf("expected forks grouped under their scanned parents, got %v", m.rows)
+	}
+	if got := header(); strings.Contains(got, "(parent") {
+		t.Fatalf("expected a healthy parent, got %q", got)
+	}
+	if got := m.groupHeader(m.rows[2].group); !strings.Contains(got, "(no parent) (parent gone)") {
+		t.Fatalf("expected the detached fork's parent to be gone, got %q", got)
+	}
+}
+
+func TestRefreshKeepsScannedParents(t *testing.T) {
+	srv := ghfake.New("me")
+	srv.AddRepo(ghfake.Repo{Owner: "up", Name: "lib"})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "one", Parent: "up/lib"})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "two", Parent: "up/lib"})
+
+	m := newFakeModel(t, srv)
+	m = drive(m, m.Init())
+	m = press(m, runes("g"))
+	m = press(m, runes("r"))
+	if len(m.rows) != 3 || m.rows[0].group.parent != "up/lib" {
+		t.Fatalf("expected both forks under up/lib after the refresh, got %v", m.rows)
+	}
+	for _, repo := range m.repos {
+		if repo.Parent != "up/lib" || repo.ParentID == 0 {
+			t.Fatalf("expected the scanned parent on %s, got %q (%d)", repo.FullName, repo.Parent, repo.ParentID)
+		}
+	}
+	m = press(m, runes("/"))
+	m = press(m, typed("up/lib")...)
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if len(m.filtered) != 2 {
+		t.Fatalf("expected the parent to stay searchable, got %v", m.filtered)
+	}
+}
+
+func TestGroupedRefreshScansNewForks(t *testing.T) {
+	srv := ghfake.New("me")
+	srv.AddRepo(ghfake.Repo{Owner: "up", Name: "lib"})
+	srv.AddRepo(ghfake.Repo{Owner: "up", Name: "tool"})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "one", Parent: "up/lib"})
+
+	m := newFakeModel(t, srv)
+	m = drive(m, m.Init())
+	m = press(m, runes("g"))
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "tool", Parent: "up/tool"})
+	m = press(m, runes("r"))
+	var parents []string
+	for _, row := range m.rows {
+		if row.header {
+			parents = append(parents, row.group.parent)
+		}
+	}
+	if strings.Join(parents, ",") != "up/lib,up/tool" {
+		t.Fatalf("expected the new fork scanned into its own group, got %v", parents)
+	}
+	if u := m.upstream["me/tool"]; u.Parent != "up/tool" {
+		t.Fatalf("expected me/tool to be scanned, got %+v", u)
+	}
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// keyMap holds every rebindable key. Names used in the "keys" section of
+// config.json are listed by bindings.
+type keyMap struct {
+	Up           key.Binding
+	Down         key.Binding
+	Toggle       key.Binding
+	SelectAll    key.Binding
+	Visual       key.Binding
+	Invert       key.Binding
+	ClearAll     key.Binding
+	SelectWhere  key.Binding
+	Filter       key.Binding
+	Delete       key.Binding
+	ForceDelete  key.Binding
+	Quarantine   key.Binding
+	MakePrivate  key.Binding
+	MakePublic   key.Binding
+	AddTopic     key.Binding
+	RemoveTopic  key.Binding
+	PurgeView    key.Binding
+	CancelAll    key.Binding
+	CancelOne    key.Binding
+	Refresh      key.Binding
+	Save         key.Binding
+	Load         key.Binding
+	Export       key.Binding
+	GroupView    key.Binding
+	Collapse     key.Binding
+	ScanUpstream key.Binding
+	Stats        key.Binding
+	Help         key.Binding
+	Quit         key.Binding
+
+	// Filter and other text prompts.
+	Apply  key.Binding
+	Cancel key.Binding
+
+	// Confirmation screen.
+	Confirm key.Binding
+	Abort   key.Binding
+}
+
+// Key modes group bindings that are active at the same time; a key may
+// only be bound once per mode.
+const (
+	keyModeNormal  = "normal"
+	keyModeFilter  = "filter"
+	keyModeConfirm = "confirm"
+)
+
+type namedBinding struct {
+	name    string
+	mode    string
+	binding *key.Binding
+}
+
+func defaultKeyMap() keyMap {
+	b := func(desc string, keys ...string) key.Binding {
+		return key.NewBinding(key.WithKeys(keys...), key.WithHelp(helpKeys(keys), desc))
+	}
+	return keyMap{
+		Up:           b("move up", "k", "up"),
+		Down:         b("move down", "j", "down"),
+		Toggle:       b("toggle selection (whole group on a header)", " "),
+		SelectAll:    b("select/deselect all visible", "a"),
+		Visual:       b("visual range selection", "v"),
+		Invert:       b("invert visible selection", "i"),
+		ClearAll:     b("clear all selections", "x"),
+		SelectWhere:  b("select where (query)", "w"),
+		Filter:       b("filter", "/"),
+		Delete:       b("delete selected", "d"),
+		ForceDelete:  b("delete incl. forks with open PRs", "D"),
+		Quarantine:   b("quarantine selected (owned repos)", "Q"),
+		MakePrivate:  b("make selected private", "p"),
+		MakePublic:   b("make selected public", "o"),
+		AddTopic:     b("add a topic to selected", "t"),
+		RemoveTopic:  b("remove a topic from selected", "T"),
+		PurgeView:    b("toggle purge view", "P"),
+		CancelAll:    b("cancel all pending actions", "u"),
+		CancelOne:    b("cancel highlighted pending action", "c"),
+		Refresh:      b("refresh", "r"),
+		Save:         b("save selection", "s"),
+		Load:         b("load selection", "L"),
+		Export:       b("export report", "e"),
+		GroupView:    b("group by parent", "g"),
+		Collapse:     b("collapse/expand group", "z"),
+		ScanUpstream: b("scan upstreams (deleted, archived, moved parents)", "U"),
+		Stats:        b("repo stats for the listed repos", "S"),
+		Help:         b("toggle help", "?"),
+		Quit:         b("quit", "q", "ctrl+c"),
+		Apply:        b("apply", "enter"),
+		Cancel:       b("cancel", "esc"),
+		Confirm:      b("confirm typed phrase", "enter"),
+		Abort:        b("cancel", "esc"),
+	}
+}
+
+// bindings lists the bindings with their config names, in help order.
+func (k *keyMap) bindings() []namedBinding {
+	return []namedBinding{
+		{"up", keyModeNormal, &k.Up},
+		{"down", keyModeNormal, &k.Down},
+		{"toggle", keyModeNormal, &k.Toggle},
+		{"select_all", keyModeNormal, &k.SelectAll},
+		{"visual", keyModeNormal, &k.Visual},
+		{"invert", keyModeNormal, &k.Invert},
+		{"clear_all", keyModeNormal, &k.ClearAll},
+		{"select_where", keyModeNormal, &k.SelectWhere},
+		{"filter", keyModeNormal, &k.Filter},
+		{"delete", keyModeNormal, &k.Delete},
+		{"force_delete", keyModeNormal, &k.ForceDelete},
+		{"quarantine", keyModeNormal, &k.Quarantine},
+		{"make_private", keyModeNormal, &k.MakePrivate},
+		{"make_public", keyModeNormal, &k.MakePublic},
+		{"add_topic", keyModeNormal, &k.AddTopic},
+		{"remove_topic", keyModeNormal, &k.RemoveTopic},
+		{"purge_view", keyModeNormal, &k.PurgeView},
+		{"cancel_all", keyModeNormal, &k.CancelAll},
+		{"cancel_one", keyModeNormal, &k.CancelOne},
+		{"refresh", keyModeNormal, &k.Refresh},
+		{"save_selection", keyModeNormal, &k.Save},
+		{"load_selection", keyModeNormal, &k.Load},
+		{"export", keyModeNormal, &k.Export},
+		{"group_view", keyModeNormal, &k.GroupView},
+		{"collapse", keyModeNormal, &k.Collapse},
+		{"scan_upstream", keyModeNormal, &k.ScanUpstream},
+		{"stats", keyModeNormal, &k.Stats},
+		{"help", keyModeNormal, &k.Help},
+		{"quit", keyModeNormal, &k.Quit},
+		{"apply", keyModeFilter, &k.Apply},
+		{"cancel", keyModeFilter, &k.Cancel},
+		{"confirm", keyModeConfirm, &k.Confirm},
+		{"abort", keyModeConfirm, &k.Abort},
+	}
+}
+
+// withOverrides returns a copy of k with the bindings named in overrides
+// replaced, e.g. {"delete": ["D"], "force_delete": ["ctrl+d"]}. It rejects
+// unknown names, empty key lists and keys bound twice in one mode.
+func (k keyMap) withOverrides(overrides map[string][]string) (keyMap, error) {
+	byName := make(map[string]*key.Binding)
+	for _, nb := range k.bindings() {
+		byName[nb.name] = nb.binding
+	}
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b, ok := byName[name]
+		if !ok {
+			return k, fmt.Errorf("keys: unknown action %q", name)
+		}
+		keys := overrides[name]
+		if len(keys) == 0 {
+			return k, fmt.Errorf("keys: %s needs at least one key", name)
+		}
+		b.SetKeys(keys...)
+		b.SetHelp(helpKeys(keys), b.Help().Desc)
+	}
+
+	seen := make(map[string]string)
+	for _, nb := range k.bindings() {
+		for _, keyName := range nb.binding.Keys() {
+			slot := nb.mode + "/" + keyName
+			if other, dup := seen[slot]; dup {
+				return k, fmt.Errorf("keys: %q is bound to both %s and %s", keyName, other, nb.name)
+			}
+			seen[slot] = nb.name
+		}
+	}
+	return k, nil
+}
+
+// keyGroup is one section of the help overlay.
+type keyGroup struct {
+	title    string
+	bindings []key.Binding
+}
+
+// groups returns the bindings grouped by mode for the help overlay.
+func (k keyMap) groups() []keyGroup {
+	titles := map[string]string{
+		keyModeNormal:  "List",
+		keyModeFilter:  "Filter and prompts",
+		keyModeConfirm: "Confirmation",
+	}
+	var out []keyGroup
+	for _, nb := range k.bindings() {
+		if len(out) == 0 || out[len(out)-1].title != titles[nb.mode] {
+			out = append(out, keyGroup{title: titles[nb.mode]})
+		}
+		g := &out[len(out)-1]
+		g.bindings = append(g.bindings, *nb.binding)
+	}
+	return out
+}
+
+// helpKeys renders keys for help text, e.g. "k/up" or "space".
+func helpKeys(keys []string) string {
+	shown := make([]string, len(keys))
+	for i, k := range keys {
+		if k == " " {
+			k = "space"
+		}
+		shown[i] = k
+	}
+	return strings.Join(shown, "/")
+}
+
+// firstKey is the key shown in short hints such as the commands line.
+func firstKey(b key.Binding) string {
+	if keys := b.Keys(); len(keys) > 0 {
+		return helpKeys(keys[:1])
+	}
+	return ""
+}
+
+// commandsLine is the short key summary under the header.
+func (m model) commandsLine() string {
+	k := m.keys
+	parts := []string{
+		firstKey(k.Down) + "/" + firstKey(k.Up) + " move",
+		firstKey(k.Toggle) + " select",
+		firstKey(k.SelectAll) + " select all",
+		firstKey(k.Filter) + " filter",
+		firstKey(k.Delete) + " delete",
+	}
+	if !m.showForks {
+		parts = append(parts, firstKey(k.Quarantine)+" quarantine", firstKey(k.PurgeView)+" purge view")
+	}
+	parts = append(parts, firstKey(k.Refresh)+" refresh", firstKey(k.Quit)+" quit")
+	return strings.Join(parts, " · ")
+}
+
+// helpView is the full-screen key reference opened with the help key.
+func (m model) helpView() string {
+	titleStyle := m.theme.title
+	keyStyle := m.theme.key
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("GitHub Fork Manager · keys") + "\n")
+	for _, g := range m.keys.groups() {
+		width := 0
+		for _, kb := range g.bindings {
+			width = max(width, len(kb.Help().Key))
+		}
+		b.WriteString("\n" + titleStyle.Render(g.title) + "\n")
+		for _, kb := range g.bindings {
+			b.WriteString("  " + keyStyle.Render(fmt.Sprintf("%-*s", width, kb.Help().Key)) + "  " + kb.Help().Desc + "\n")
+		}
+	}
+	b.WriteString("\nKeys can be remapped under \"keys\" in config.json. Press any key to close.\n")
+	return b.String()
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKeyOverrides(t *testing.T) {
+	if _, err := defaultKeyMap().withOverrides(map[string][]string{"nuke": {"n"}}); err == nil || !strings.Contains(err.Error(), "unknown action") {
+		t.Fatalf("expected unknown action error, got %v", err)
+	}
+	if _, err := defaultKeyMap().withOverrides(map[string][]string{"delete": {"D"}}); err == nil || !strings.Contains(err.Error(), "force_delete") {
+		t.Fatalf("expected conflict with force_delete, got %v", err)
+	}
+	if _, err := defaultKeyMap().withOverrides(map[string][]string{"refresh": {}}); err == nil {
+		t.Fatalf("expected error for empty key list")
+	}
+	// Keys may repeat across modes: enter applies a filter and confirms.
+	if _, err := defaultKeyMap().withOverrides(map[string][]string{"apply": {"enter", "tab"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRemappedDeleteKey(t *testing.T) {
+	m := newFakeModel(t, flowServer())
+	var err error
+	m.keys, err = m.keys.withOverrides(map[string][]string{"delete": {"D"}, "force_delete": {"ctrl+d"}})
+	if err != nil {
+		t.Fatalf("overrides: %v", err)
+	}
+	m = drive(m, m.Init())
+	m = press(m, keySpace, runes("d"))
+	if m.mode != modeNormal {
+		t.Fatalf("d should no longer delete")
+	}
+	if !strings.Contains(m.View(), "D delete") {
+		t.Fatalf("commands line should show the remapped key:\n%s", m.View())
+	}
+	m = press(m, runes("D"))
+	if m.mode != modeConfirm {
+		t.Fatalf("D should open the confirmation, status %q", m.status)
+	}
+
+	m = press(m, keyEsc, runes("?"))
+	help := m.View()
+	for _, want := range []string{"List", "Filter and prompts", "Confirmation", "ctrl+d", "delete selected"} {
+		if !strings.Contains(help, want) {
+			t.Fatalf("help overlay missing %q:\n%s", want, help)
+		}
+	}
+	m = press(m, runes("j"))
+	if m.showHelp || m.cursor != 0 {
+		t.Fatalf("any key should only close the help overlay")
+	}
+}
@@ -0,0 +1,344 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/muesli/reflow/truncate"
+
+	"github.com/seeg/github-fork-manager/internal/gh"
+)
+
+// When the columns do not fit, compact mode drops them, lowest priority
+// first. From detailWidth on, a detail pane for the repo under the cursor
+// is shown next to the list.
+const (
+	detailWidth     = 140
+	detailPaneWidth = 46
+	columnGap       = 2
+	// rowPrefix is the cursor and checkbox before the first column.
+	rowPrefix = len("> [ ] ")
+)
+
+// Column ids, in display order.
+const (
+	colName = iota
+	colLanguage
+	colVisibility
+	colPushed
+	colSize
+	colParent
+	colNotes
+)
+
+// column is one laid-out list column. Flexible columns share the space the
+// fixed ones leave, from their minimum up to their widest cell.
+type column struct {
+	id    int
+	title string
+	width int
+	flex  bool
+	right bool
+}
+
+var listColumns = []column{
+	{id: colName, title: "Name", width: 16, flex: true},
+	{id: colLanguage, title: "Language", width: 10},
+	{id: colVisibility, title: "Visibility", width: 10},
+	{id: colPushed, title: "Pushed", width: 10},
+	{id: colSize, title: "Size", width: 8, right: true},
+	{id: colParent, title: "Parent", width: 12, flex: true},
+	{id: colNotes, title: "Notes", width: 12, flex: true},
+}
+
+// dropOrder is the order compact mode gives up columns in. Name, pushed
+// date and the safety notes always stay.
+var dropOrder = []int{colSize, colParent, colLanguage, colVisibility}
+
+// listLayout is how the list is drawn at the current width.
+type listLayout struct {
+	columns []column
+	width   int
+	compact bool
+	detail  bool
+}
+
+// layout fits the columns into the terminal width. A zero width means the
+// size is not known yet; rows are then written out in full.
+func (m model) layout() listLayout {
+	if m.width <= 0 {
+		return listLayout{}
+	}
+	l := listLayout{width: m.width}
+	if m.width >= detailWidth {
+		l.detail = true
+		l.width = m.width - detailPaneWidth - len(" │ ")
+	}
+	cols := append([]column(nil), listColumns...)
+	need := func() int { return listLayout{columns: cols}.used() }
+	for _, id := range dropOrder {
+		if need() <= l.width {
+			break
+		}
+		for i, c := range cols {
+			if c.id == id {
+				cols = append(cols[:i], cols[i+1:]...)
+				l.compact = true
+				break
+			}
+		}
+	}
+
+	// Grow the flexible columns towards their widest cell. When there is
+	// not room for all of them, each gets a share of what it is missing.
+	widest := m.widestCells()
+	spare := l.width - need()
+	missing := 0
+	for _, c := range cols {
+		if c.flex {
+			missing += max(widest[c.id]-c.width, 0)
+		}
+	}
+	if spare > 0 && missing > 0 {
+		budget := min(spare, missing)
+		for i, c := range cols {
+			if !c.flex {
+				continue
+			}
+			grow := max(widest[c.id]-c.width, 0) * budget / missing
+			cols[i].width += grow
+			spare -= grow
+		}
+	}
+	if spare < 0 {
+		// Still too narrow: the name gives way, down to a sliver.
+		cols[0].width = max(cols[0].width+spare, 4)
+	}
+	l.columns = cols
+	return l
+}
+
+// widestCells is the widest plain cell of each flexible column over the
+// whole list, so columns do not jump while scrolling.
+func (m model) widestCells() map[int]int {
+	widest := map[int]int{}
+	for _, row := range m.rows {
+		if row.header {
+			continue
+		}
+		for _, id := range []int{colName, colParent, colNotes} {
+			w := runewidth.StringWidth(m.cellText(row.repo, id))
+			if id == colName && m.grouped {
+				w += 2
+			}
+			widest[id] = max(widest[id], w)
+		}
+	}
+	return widest
+}
+
+// cellText is the plain text of a repo's cell in column id.
+func (m model) cellText(repo gh.Repo, id int) string {
+	switch id {
+	case colName:
+		return repo.FullName
+	case colLanguage:
+		return repo.Language
+	case colVisibility:
+		if repo.Private {
+			return "private"
+		}
+		return "public"
+	case colPushed:
+		if repo.PushedAt.IsZero() {
+			return "unknown"
+		}
+		return repo.PushedAt.Format("2006-01-02")
+	case colSize:
+		return formatSize(repo.Size)
+	case colParent:
+		return repo.Parent
+	case colNotes:
+		notes := m.badges(repo)
+		if repo.Archived {
+			notes = append([]string{"archived"}, notes...)
+		}
+		if topics := shownTopics(repo); len(topics) > 0 {
+			notes = append(notes, "#"+strings.Join(topics, " #"))
+		}
+		return strings.Join(notes, " · ")
+	}
+	return ""
+}
+
+// columnTitles renders the column header line.
+func (l listLayout) columnTitles() string {
+	cells := make([]string, len(l.columns))
+	for i, c := range l.columns {
+		cells[i] = pad(c.title, c.width, c.right)
+	}
+	return strings.Repeat(" ", rowPrefix) + strings.TrimRight(strings.Join(cells, strings.Repeat(" ", columnGap)), " ")
+}
+
+// repoColumns renders a repo's cells after its cursor and checkbox.
+// Cells are cut to their column with an ellipsis; filter matches and the
+// selection are styled only after cutting so widths stay exact.
+func (m model) repoColumns(l listLayout, repo gh.Repo, pos matchPositions) string {
+	cells := make([]string, len(l.columns))
+	for i, c := range l.columns {
+		text := m.cellText(repo, c.id)
+		width := c.width
+		indent := ""
+		if c.id == colName && m.grouped {
+			indent = "  "
+			width = max(width-2, 1)
+		}
+		cut := runewidth.Truncate(text, width, "…")
+		shown := pad(cut, width, c.right)
+		styled := cut
+		switch c.id {
+		case colName:
+			styled = m.theme.highlight(cut, visiblePositions(cut, text, pos.name))
+			if m.selected[repo.FullName] && m.theme.mono {
+				styled = m.theme.selected.Render(styled)
+			}
+			styled = hyperlink(repo.HTMLURL, styled)
+		case colLanguage:
+			styled = m.theme.highlight(cut, visiblePositions(cut, text, pos.language))
+		case colParent:
+			styled = m.theme.highlight(cut, visiblePositions(cut, text, pos.parent))
+		case colVisibility:
+			if repo.Private && m.theme.mono {
+				styled = lipgloss.NewStyle().Bold(true).Render(cut)
+			}
+		case colNotes:
+			if cut != "" {
+				styled = m.theme.badge.Render(cut)
+			}
+		}
+		cells[i] = indent + strings.Replace(shown, cut, styled, 1)
+	}
+	return strings.Join(cells, strings.Repeat(" ", columnGap))
+}
+
+// used is the width of a full row: cursor, checkbox and every column.
+func (l listLayout) used() int {
+	n := rowPrefix
+	for i, c := range l.columns {
+		if i > 0 {
+			n += columnGap
+		}
+		n += c.width
+	}
+	return n
+}
+
+// visiblePositions keeps the match positions that survived truncation.
+// The ellipsis itself is never highlighted.
+func visiblePositions(cut, text string, positions []int) []int {
+	if cut == text {
+		return positions
+	}
+	kept := len([]rune(cut)) - 1
+	var out []int
+	for _, p := range positions {
+		if p < kept {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// pad fills s with spaces to width, on the left for right-aligned columns.
+func pad(s string, width int, right bool) string {
+	gap := width - runewidth.StringWidth(s)
+	if gap <= 0 {
+		return s
+	}
+	if right {
+		return strings.Repeat(" ", gap) + s
+	}
+	return s + strings.Repeat(" ", gap)
+}
+
+// fit cuts a styled line to width, keeping its escape sequences intact.
+func fit(line string, width int) string {
+	if width <= 0 {
+		return line
+	}
+	return truncate.StringWithTail(line, uint(width), "…")
+}
+
+// detailLines describes the repo under the cursor for the detail pane.
+func (m model) detailLines() []string {
+	repo, ok := m.cursorRepo()
+	if !ok {
+		return nil
+	}
+	lines := []string{m.theme.title.Render(repo.FullName), ""}
+	field := func(label, value string) {
+		if value != "" {
+			lines = append(lines, m.theme.muted.Render(fmt.Sprintf("%-10s", label))+value)
+		}
+	}
+	field("Language", repo.Language)
+	field("Visible", m.cellText(repo, colVisibility))
+	if repo.Archived {
+		field("State", "archived")
+	}
+	field("Pushed", m.cellText(repo, colPushed))
+	field("Size", formatSize(repo.Size))
+	field("Branch", repo.DefaultBranch)
+	field("Parent", repo.Parent)
+	field("Topics", strings.Join(shownTopics(repo), ", "))
+	if u, ok := m.upstream[repo.FullName]; ok {
+		field("Upstream", u.String())
+		field("Source", u.Source)
+	}
+	field("URL", repo.HTMLURL)
+	if badges := m.badges(repo); len(badges) > 0 {
+		lines = append(lines, "")
+		for _, badge := range badges {
+			lines = append(lines, m.theme.badge.Render("• "+badge))
+		}
+	}
+	for i, line := range lines {
+		lines[i] = fit(line, detailPaneWidth)
+	}
+	return lines
+}
+
+// besideDetail puts the detail pane to the right of the list lines, which
+// must already be padded to the list width.
+func (m model) besideDetail(list []string) []string {
+	detail := m.detailLines()
+	n := max(len(list), len(detail))
+	out := make([]string, n)
+	for i := 0; i < n; i++ {
+		var left, right string
+		if i < len(list) {
+			left = list[i]
+		}
+		if i < len(detail) {
+			right = detail[i]
+		}
+		if i >= len(list) {
+			left = strings.Repeat(" ", m.layout().width)
+		}
+		out[i] = strings.TrimRight(left+" │ "+right, " ")
+	}
+	return out
+}
+
+// formatSize renders a size reported in kilobytes, as GitHub does.
+func formatSize(kb int) string {
+	switch {
+	case kb < 1024:
+		return fmt.Sprintf("%d KB", kb)
+	case kb < 1024*1024:
+		return fmt.Sprintf("%.1f MB", float64(kb)/1024)
+	}
+	return fmt.Sprintf("%.1f GB", float64(kb)/(1024*1024))
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
@@ -9,12 +10,18 @@ import (
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/seeg/github-fork-manager/internal/config"
 	"github.com/seeg/github-fork-manager/internal/gh"
+	"github.com/seeg/github-fork-manager/internal/ghfake"
+	"github.com/seeg/github-fork-manager/internal/gitea"
+	"github.com/seeg/github-fork-manager/internal/gitlab"
+	"github.com/seeg/github-fork-manager/internal/report"
+	"github.com/seeg/github-fork-manager/internal/selection"
 )
 
 var version = "dev"
@@ -25,54 +32,217 @@ const (
 	modeNormal mode = iota
 	modeFiltering
 	modeConfirm
+	modeSelectWhere
+	modeSaveSet
+	modeLoadSet
+	modeExport
+	modeAddTopic
+	modeRemoveTopic
 )
 
 type model struct {
-	cfg           config.Config
-	client        gh.Client
-	showForks     bool
-	repos         []gh.Repo
-	filtered      []gh.Repo
-	cursor        int
-	listOffset    int
-	listHeight    int
+	cfg        config.Config
+	client     gh.Provider
+	showForks  bool
+	repos      []gh.Repo
+	filtered   []gh.Repo
+	cursor     int
+	listOffset int
+	listHeight int
+	// width is the terminal width, 0 until the first resize.
+	width         int
 	selected      map[string]bool
 	status        string
 	err           error
 	loading       bool
-	deleting      bool
-	deleteQueue   []gh.Repo
-	deleteResults map[string]string
+	running       bool
+	action        batchAction
+	queue         []gh.Repo
+	results       map[string]string
 	filterInput   textinput.Model
 	mode          mode
 	userLogin     string
 	confirmInput  textinput.Model
 	confirmExpect string
+	confirmPrompt string
+	confirmNext   []confirmStep
+	openPRs       map[string][]gh.PullRequest
+	prsLoading    bool
+	uniqueness    map[string]gh.Uniqueness
+	scanQueue     []gh.Repo
+	uniqueAcked   bool
+	dryRun        bool
+	graceActive   bool
+	graceUntil    time.Time
+	purgeView     bool
+	whereInput    textinput.Model
+	visual        bool
+	visualAnchor  int
+	visualBase    map[string]bool
+	nameInput     textinput.Model
+	activeSet     string
+	pendingSet    *selection.Set
+	pendingImport *importedList
+	unmatched     []string
+	history       []report.Entry
+	keys          keyMap
+	showHelp      bool
+	theme         theme
+	// historyTitle names the batch in history. It is fixed when the batch
+	// starts, since m.action moves on with the next prompt.
+	historyTitle string
+	// batchTopic is the topic a tag or untag batch applies; topicHistory
+	// holds its results apart from the last real batch in history.
+	batchTopic   string
+	topicHistory []report.Entry
+	// stats is the snapshot shown on the stats screen while showStats.
+	stats       repoStats
+	statsCursor int
+	showStats   bool
+	// rows is the laid-out list the cursor moves over; see buildRows.
+	rows           []listRow
+	grouped        bool
+	collapsed      map[string]bool
+	parents        map[string]parentStatus
+	parentQueue    []string
+	parentChecking bool
+	// upstream holds scanned parent/source states per fork.
+	upstream        map[string]gh.Upstream
+	upstreamQueue   []gh.Repo
+	upstreamScanned bool
+	// knownParents is the parent each fork had at its last upstream scan,
+	// by fork ID; see knownParent.
+	knownParents map[int64]knownParent
+	// filterSeq counts filter edits so stale debounced refilters are
+	// dropped.
+	filterSeq int
+	// filterBefore is the filter as it was when editing started, restored
+	// on cancel.
+	filterBefore string
+	// index holds each repo's fields prepared for fuzzy matching.
+	index map[string]repoIndex
 }
 
-func newModel(cfg config.Config, showForks bool) model {
+// importedList is a --select-from list waiting for the repos to load.
+type importedList struct {
+	source  string
+	entries []string
+}
+
+// batchAction is the operation a confirmed batch applies to each queued
+// repo.
+type batchAction int
+
+const (
+	actionDelete batchAction = iota
+	actionQuarantine
+	actionMakePrivate
+	actionMakePublic
+	actionTag
+	actionUntag
+)
+
+func (a batchAction) String() string {
+	switch a {
+	case actionQuarantine:
+		return "quarantine"
+	case actionMakePrivate:
+		return "make private"
+	case actionMakePublic:
+		return "make public"
+	case actionTag:
+		return "tag"
+	case actionUntag:
+		return "untag"
+	}
+	return "delete"
+}
+
+func (a batchAction) title() string {
+	switch a {
+	case actionQuarantine:
+		return "Quarantine"
+	case actionMakePrivate:
+		return "Make private"
+	case actionMakePublic:
+		return "Make public"
+	case actionTag:
+		return "Tag"
+	case actionUntag:
+		return "Untag"
+	}
+	return "Delete"
+}
+
+// plural names several queued actions, e.g. "3 deletes".
+func (a batchAction) plural() string {
+	if a == actionMakePrivate || a == actionMakePublic {
+		return "visibility changes"
+	}
+	return a.String() + "s"
+}
+
+func (a batchAction) progressive() string {
+	switch a {
+	case actionQuarantine:
+		return "Quarantining"
+	case actionMakePrivate:
+		return "Making private"
+	case actionMakePublic:
+		return "Making public"
+	case actionTag:
+		return "Tagging"
+	case actionUntag:
+		return "Untagging"
+	}
+	return "Deleting"
+}
+
+// confirmStep is one typed acknowledgment on the confirmation screen. Risky
+// batches chain several steps; deletion starts after the last one.
+type confirmStep struct {
+	expect string
+	prompt string
+}
+
+func newModel(cfg config.Config, client gh.Provider, showForks bool) model {
 	ti := textinput.New()
-	ti.Placeholder = "type to filter (owner/name, language); enter to apply, esc to clear"
+	ti.Placeholder = "type to filter (owner/name, language); enter to apply, esc to restore"
 	ti.CharLimit = 64
 	ti.Prompt = "/ "
 
+	wi := textinput.New()
+	wi.Placeholder = "select every repo matching (owner/name, language); enter to select, esc to cancel"
+	wi.CharLimit = 64
+	wi.Prompt = "select where> "
+
+	ni := textinput.New()
+	ni.CharLimit = 64
+	ni.Prompt = "name> "
+
 	ci := textinput.New()
 	ci.Placeholder = "your-username approves"
 	ci.CharLimit = 64
 	ci.Prompt = "confirm> "
 
 	return model{
-		cfg:           cfg,
-		client:        gh.New(cfg.APIBase, cfg.Token),
-		showForks:     showForks,
-		selected:      make(map[string]bool),
-		deleteResults: make(map[string]string),
-		filterInput:   ti,
-		confirmInput:  ci,
-		loading:       true,
-		status:        "Loading forks…",
-		mode:          modeNormal,
-		listHeight:    15,
+		cfg:          cfg,
+		client:       client,
+		dryRun:       cfg.DryRun,
+		showForks:    showForks,
+		selected:     make(map[string]bool),
+		results:      make(map[string]string),
+		uniqueness:   make(map[string]gh.Uniqueness),
+		filterInput:  ti,
+		whereInput:   wi,
+		nameInput:    ni,
+		confirmInput: ci,
+		loading:      true,
+		status:       "Loading forks…",
+		mode:         modeNormal,
+		listHeight:   15,
+		keys:         defaultKeyMap(),
+		theme:        defaultTheme(),
 	}
 }
 
@@ -93,12 +263,30 @@ type userLoadedMsg struct {
 	err   error
 }
 
-type deleteResultMsg struct {
-	repo gh.Repo
-	err  error
+type pullsLoadedMsg struct {
+	prs map[string][]gh.PullRequest
+	err error
+}
+
+type uniquenessMsg struct {
+	repo   gh.Repo
+	result gh.Uniqueness
 }
 
-func loadReposCmd(client gh.Client, showForks bool) tea.Cmd {
+type graceTickMsg time.Time
+
+// actionResultMsg reports one finished batch action. updated is the repo
+// as it looks afterwards, for actions that keep it around; unchanged is
+// set when there was nothing to do.
+type actionResultMsg struct {
+	action    batchAction
+	repo      gh.Repo
+	updated   gh.Repo
+	err       error
+	unchanged bool
+}
+
+func loadReposCmd(client gh.Provider, showForks bool) tea.Cmd {
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
 		defer cancel()
@@ -107,16 +295,71 @@ func loadReposCmd(client gh.Client, showForks bool) tea.Cmd {
 	}
 }
 
-func deleteNextCmd(client gh.Client, repo gh.Repo) tea.Cmd {
+func runNextCmd(client gh.Provider, action batchAction, repo gh.Repo, prefix, topic string) tea.Cmd {
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
 		defer cancel()
+		if action == actionQuarantine {
+			editor, ok := gh.Lookup[gh.RepoEditor](client)
+			if !ok {
+				return actionResultMsg{action: action, repo: repo, err: gh.ErrUnsupported}
+			}
+			updated, err := gh.Quarantine(ctx, editor, repo, prefix, time.Now())
+			return actionResultMsg{action: action, repo: repo, updated: updated, err: err}
+		}
+		if action == actionTag || action == actionUntag {
+			editor, ok := gh.Lookup[gh.RepoEditor](client)
+			if !ok {
+				return actionResultMsg{action: action, repo: repo, err: gh.ErrUnsupported}
+			}
+			add, remove := []string{topic}, []string(nil)
+			if action == actionUntag {
+				add, remove = nil, add
+			}
+			topics, changed, err := gh.EditTopics(ctx, editor, repo.FullName, add, remove)
+			updated := repo
+			updated.Topics = topics
+			return actionResultMsg{action: action, repo: repo, updated: updated, err: err, unchanged: !changed}
+		}
+		if action == actionMakePrivate || action == actionMakePublic {
+			setter, ok := gh.Lookup[gh.VisibilitySetter](client)
+			if !ok {
+				return actionResultMsg{action: action, repo: repo, err: gh.ErrUnsupported}
+			}
+			updated := repo
+			updated.Private = action == actionMakePrivate
+			err := setter.SetVisibility(ctx, repo.FullName, updated.Private)
+			return actionResultMsg{action: action, repo: repo, updated: updated, err: err}
+		}
 		err := client.DeleteRepo(ctx, repo.FullName)
-		return deleteResultMsg{repo: repo, err: err}
+		return actionResultMsg{action: action, repo: repo, err: err}
+	}
+}
+
+func loadPullsCmd(finder gh.PullRequestFinder, login string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		defer cancel()
+		prs, err := finder.OpenPullRequestsByFork(ctx, login)
+		return pullsLoadedMsg{prs: prs, err: err}
+	}
+}
+
+func scanUniqueCmd(scanner gh.UniquenessScanner, repo gh.Repo) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		defer cancel()
+		return uniquenessMsg{repo: repo, result: scanner.ScanUniqueness(ctx, repo)}
 	}
 }
 
-func loadUserCmd(client gh.Client) tea.Cmd {
+func graceTickCmd() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+		return graceTickMsg(t)
+	})
+}
+
+func loadUserCmd(client gh.Provider) tea.Cmd {
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
@@ -127,8 +370,12 @@ func loadUserCmd(client gh.Client) tea.Cmd {
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case tea.MouseMsg:
+		m.handleMouse(msg)
+		return m, nil
 	case tea.WindowSizeMsg:
-		m.listHeight = msg.Height - 8 // leave room for header/footer lines
+		m.width = msg.Width
+		m.listHeight = msg.Height - 9 // leave room for header/footer lines
 		if m.listHeight < 5 {
 			m.listHeight = 5
 		}
@@ -136,43 +383,126 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 	case reposLoadedMsg:
 		m.loading = false
+		m.visual = false
 		m.err = msg.err
 		if msg.err == nil {
 			m.repos = sortRepos(msg.repos)
-			m.filtered = m.applyFilter(m.filterInput.Value())
+			m.applyScannedParents()
+			m.reindex()
+			m.setFiltered(m.applyFilter(m.filterInput.Value()))
 			label := "repos"
 			if m.showForks {
 				label = "forks"
 			}
 			m.status = fmt.Sprintf("Loaded %d %s", len(m.repos), label)
+			if m.pendingSet != nil {
+				m.applySet(*m.pendingSet)
+				m.pendingSet = nil
+			} else if missing := m.reconcileSelection(); len(missing) > 0 {
+				m.status += " · " + missingNote(missing)
+			}
+			if m.pendingImport != nil {
+				m.applyImport(*m.pendingImport)
+				m.pendingImport = nil
+			}
 			m.ensureVisible()
-		} else {
-			m.status = "Failed to load forks"
+			cmd := m.startPullScan()
+			if m.grouped {
+				cmd = tea.Batch(cmd, m.startParentCheck())
+			}
+			return m, cmd
 		}
+		m.status = "Failed to load forks"
 		return m, nil
 	case userLoadedMsg:
 		if msg.err == nil && msg.login != "" {
 			m.userLogin = msg.login
+			cmd := m.startPullScan()
+			return m, cmd
 		}
 		return m, nil
-	case deleteResultMsg:
-		m.deleting = len(m.deleteQueue) > 1
-		m.deleteQueue = popQueue(m.deleteQueue)
+	case pullsLoadedMsg:
+		m.prsLoading = false
 		if msg.err != nil {
-			m.deleteResults[msg.repo.FullName] = "error: " + msg.err.Error()
+			m.openPRs = nil
+			m.status = "Could not check open pull requests: " + msg.err.Error()
+			return m, nil
+		}
+		m.openPRs = msg.prs
+		return m, nil
+	case parentCheckedMsg:
+		cmd := m.recordParent(msg)
+		return m, cmd
+	case upstreamMsg:
+		cmd := m.recordUpstream(msg)
+		return m, cmd
+	case filterDebounceMsg:
+		if msg.seq == m.filterSeq && m.mode == modeFiltering {
+			m.refilterLive()
+		}
+		return m, nil
+	case graceTickMsg:
+		if !m.graceActive {
+			return m, nil
+		}
+		if time.Time(msg).Before(m.graceUntil) {
+			return m, graceTickCmd()
+		}
+		m.graceActive = false
+		if len(m.queue) == 0 {
+			m.status = "Nothing left to " + m.action.String()
+			return m, nil
+		}
+		cmd := m.startBatch()
+		return m, cmd
+	case uniquenessMsg:
+		m.uniqueness[msg.repo.FullName] = msg.result
+		m.scanQueue = popQueue(m.scanQueue)
+		if len(m.scanQueue) > 0 {
+			if scanner, ok := gh.Lookup[gh.UniquenessScanner](m.client); ok {
+				return m, scanUniqueCmd(scanner, m.scanQueue[0])
+			}
+		}
+		return m, nil
+	case actionResultMsg:
+		m.running = len(m.queue) > 1
+		m.queue = popQueue(m.queue)
+		switch {
+		case msg.action == actionQuarantine:
+			m.recordQuarantine(msg)
+		case msg.action == actionMakePrivate || msg.action == actionMakePublic:
+			m.recordVisibility(msg)
+		case msg.action == actionTag || msg.action == actionUntag:
+			m.recordTopic(msg)
+		case errors.Is(msg.err, gh.ErrDeleteScheduled):
+			m.results[msg.repo.FullName] = "scheduled for deletion"
+			m.status = fmt.Sprintf("Scheduled %s for deletion", msg.repo.FullName)
+			m.removeRepo(msg.repo.FullName)
+			delete(m.selected, msg.repo.FullName)
+		case msg.err != nil:
+			m.results[msg.repo.FullName] = "error: " + msg.err.Error()
 			m.status = fmt.Sprintf("Failed to delete %s", msg.repo.FullName)
-		} else {
-			m.deleteResults[msg.repo.FullName] = "deleted"
+		case m.dryRun:
+			// Keep the repo listed; nothing changed on the server.
+			m.results[msg.repo.FullName] = "would delete"
+			m.status = fmt.Sprintf("Would delete %s (dry run)", msg.repo.FullName)
+			delete(m.selected, msg.repo.FullName)
+		default:
+			m.results[msg.repo.FullName] = "deleted"
 			m.status = fmt.Sprintf("Deleted %s", msg.repo.FullName)
 			m.removeRepo(msg.repo.FullName)
 			delete(m.selected, msg.repo.FullName)
 		}
-		logLine(m.cfg.LogPath, fmt.Sprintf("delete %s -> %s", msg.repo.FullName, m.deleteResults[msg.repo.FullName]))
+		m.recordHistory(msg.repo)
+		logLine(m.cfg.LogPath, fmt.Sprintf("%s %s -> %s", msg.action, msg.repo.FullName, m.results[msg.repo.FullName]))
 
-		if len(m.deleteQueue) > 0 {
-			return m, deleteNextCmd(m.client, m.deleteQueue[0])
+		if len(m.queue) > 0 {
+			return m, runNextCmd(m.client, m.action, m.queue[0], m.cfg.QuarantinePrefix, m.batchTopic)
+		}
+		m.running = false
+		if m.action == actionTag || m.action == actionUntag {
+			m.status = m.topicSummary()
 		}
-		m.deleting = false
 		return m, nil
 	}
 
@@ -182,105 +512,760 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.mode == modeConfirm {
 			var cmd tea.Cmd
 			m.confirmInput, cmd = m.confirmInput.Update(msg)
-			switch msg.Type {
-			case tea.KeyEnter:
+			switch {
+			case key.Matches(msg, m.keys.Confirm):
 				if m.confirmInput.Value() == m.confirmExpect {
+					if len(m.confirmNext) > 0 {
+						m.setConfirmStep(m.confirmNext[0])
+						m.confirmNext = m.confirmNext[1:]
+						m.status = "Additional confirmation required"
+						return m, cmd
+					}
+					if n := m.pendingScans(); n > 0 && m.action == actionDelete {
+						m.status = fmt.Sprintf("Waiting for unique-commit scan of %d repos; press Enter again when done", n)
+						return m, cmd
+					}
+					if step, ok := m.uniqueWorkStep(); ok && !m.uniqueAcked && m.action == actionDelete {
+						m.uniqueAcked = true
+						m.setConfirmStep(step)
+						m.status = "Some repos may hold unique work; acknowledgment required"
+						return m, cmd
+					}
 					m.mode = modeNormal
 					m.confirmInput.Blur()
-					if len(m.deleteQueue) == 0 {
+					m.history = nil
+					m.historyTitle = m.action.title() + " batch"
+					if len(m.queue) == 0 {
 						m.status = "Nothing selected"
 						return m, cmd
 					}
-					m.deleting = true
-					m.status = fmt.Sprintf("Deleting %d repos…", len(m.deleteQueue))
-					return m, tea.Batch(cmd, deleteNextCmd(m.client, m.deleteQueue[0]))
+					if m.cfg.Grace > 0 {
+						m.graceActive = true
+						m.graceUntil = time.Now().Add(m.cfg.Grace)
+						m.status = fmt.Sprintf("%s %d repos in %s · %s cancel all · %s cancel highlighted", m.action.progressive(), len(m.queue), m.cfg.Grace, firstKey(m.keys.CancelAll), firstKey(m.keys.CancelOne))
+						return m, tea.Batch(cmd, graceTickCmd())
+					}
+					start := m.startBatch()
+					return m, tea.Batch(cmd, start)
 				}
 				m.status = fmt.Sprintf("Type exact confirmation: %q", m.confirmExpect)
-			case tea.KeyEsc:
+			case key.Matches(msg, m.keys.Abort):
 				m.mode = modeNormal
 				m.confirmInput.Blur()
-				m.deleteQueue = nil
-				m.status = "Delete cancelled"
+				m.queue = nil
+				m.confirmNext = nil
+				m.status = m.action.title() + " cancelled"
 			}
 			return m, cmd
 		}
 
 		if m.mode == modeFiltering {
+			before := m.filterInput.Value()
 			var cmd tea.Cmd
 			m.filterInput, cmd = m.filterInput.Update(msg)
-			switch msg.Type {
-			case tea.KeyEnter:
+			switch {
+			case key.Matches(msg, m.keys.Apply):
 				m.mode = modeNormal
-				m.cursor = 0
-				m.filtered = m.applyFilter(m.filterInput.Value())
+				m.refilterKeepingCursor()
 				m.status = fmt.Sprintf("Filter applied: %d shown", len(m.filtered))
-				m.ensureVisible()
-			case tea.KeyEsc:
+				if m.filterInput.Value() == "" {
+					m.status = "Filter cleared"
+				}
+				if bad := unknownQualifier(m.filterInput.Value()); bad != "" {
+					m.status = fmt.Sprintf("Unknown filter term %q; it matches nothing", bad)
+				}
+				return m, tea.Batch(cmd, m.upstreamFilterCmd())
+			case key.Matches(msg, m.keys.Cancel):
 				m.mode = modeNormal
-				m.filterInput.SetValue("")
-				m.filtered = m.applyFilter("")
+				m.filterSeq++
+				m.filterInput.SetValue(m.filterBefore)
+				m.filterInput.CursorEnd()
+				m.refilterKeepingCursor()
 				m.status = "Filter cleared"
-				m.ensureVisible()
+				if m.filterBefore != "" {
+					m.status = fmt.Sprintf("Filter restored: %d shown", len(m.filtered))
+				}
+			default:
+				if m.filterInput.Value() != before {
+					return m, tea.Batch(cmd, m.liveFilter())
+				}
+			}
+			return m, cmd
+		}
+
+		if m.mode == modeSelectWhere {
+			var cmd tea.Cmd
+			m.whereInput, cmd = m.whereInput.Update(msg)
+			switch {
+			case key.Matches(msg, m.keys.Apply):
+				m.mode = modeNormal
+				m.whereInput.Blur()
+				m.selectWhere(m.whereInput.Value())
+			case key.Matches(msg, m.keys.Cancel):
+				m.mode = modeNormal
+				m.whereInput.Blur()
+				m.status = "Select where cancelled"
 			}
 			return m, cmd
 		}
 
-		switch msg.String() {
-		case "ctrl+c", "q":
+		if m.namePrompt() {
+			var cmd tea.Cmd
+			m.nameInput, cmd = m.nameInput.Update(msg)
+			switch {
+			case key.Matches(msg, m.keys.Apply):
+				name := strings.TrimSpace(m.nameInput.Value())
+				m.nameInput.Blur()
+				var next tea.Cmd
+				switch m.mode {
+				case modeSaveSet:
+					m.saveSet(name)
+				case modeLoadSet:
+					m.loadSet(name)
+				case modeExport:
+					m.exportReport(name)
+				case modeAddTopic, modeRemoveTopic:
+					next = m.beginTopic(name, m.mode == modeAddTopic)
+				}
+				m.mode = modeNormal
+				return m, tea.Batch(cmd, next)
+			case key.Matches(msg, m.keys.Cancel):
+				m.mode = modeNormal
+				m.nameInput.Blur()
+				m.status = "Cancelled"
+			}
+			return m, cmd
+		}
+
+		if m.showHelp {
+			m.showHelp = false
+			return m, nil
+		}
+
+		if m.showStats {
+			switch {
+			case key.Matches(msg, m.keys.Up):
+				if m.statsCursor > 0 {
+					m.statsCursor--
+				}
+			case key.Matches(msg, m.keys.Down):
+				if m.statsCursor < len(m.stats.buckets())-1 {
+					m.statsCursor++
+				}
+			case key.Matches(msg, m.keys.Apply):
+				cmd := m.jumpToBucket()
+				return m, cmd
+			case key.Matches(msg, m.keys.Cancel, m.keys.Stats):
+				m.showStats = false
+			}
+			return m, nil
+		}
+
+		if m.visual {
+			switch {
+			case key.Matches(msg, m.keys.Up, m.keys.Down):
+			case key.Matches(msg, m.keys.Visual, m.keys.Cancel):
+				m.visual = false
+				m.visualBase = nil
+				m.status = fmt.Sprintf("Visual mode off: %d selected", len(m.selected))
+				return m, nil
+			default:
+				m.visual = false
+				m.visualBase = nil
+			}
+		}
+
+		switch {
+		case msg.Type == tea.KeyCtrlC || key.Matches(msg, m.keys.Quit):
+			if m.graceActive {
+				m.cancelQueued(m.queue)
+			}
 			return m, tea.Quit
-		case "j", "down":
-			if m.cursor < len(m.filtered)-1 {
+		case key.Matches(msg, m.keys.CancelAll):
+			if !m.graceActive {
+				return m, nil
+			}
+			n := len(m.queue)
+			m.cancelQueued(m.queue)
+			m.graceActive = false
+			m.status = fmt.Sprintf("Cancelled %d pending %s", n, m.action.plural())
+		case key.Matches(msg, m.keys.CancelOne):
+			repo, ok := m.cursorRepo()
+			if !m.graceActive || !ok {
+				return m, nil
+			}
+			if !m.isQueued(repo.FullName) {
+				m.status = fmt.Sprintf("%s is not queued", repo.FullName)
+				return m, nil
+			}
+			m.cancelQueued([]gh.Repo{repo})
+			m.status = fmt.Sprintf("Cancelled %s of %s; %d still pending", m.action, repo.FullName, len(m.queue))
+		case key.Matches(msg, m.keys.Down):
+			if m.cursor < len(m.rows)-1 {
 				m.cursor++
 				m.ensureVisible()
 			}
-		case "k", "up":
+			if m.visual {
+				m.extendVisual()
+			}
+		case key.Matches(msg, m.keys.Up):
 			if m.cursor > 0 {
 				m.cursor--
 				m.ensureVisible()
 			}
-		case "r":
+			if m.visual {
+				m.extendVisual()
+			}
+		case key.Matches(msg, m.keys.Refresh):
 			m.loading = true
 			m.status = "Refreshing…"
 			return m, loadReposCmd(m.client, m.showForks)
-		case "/":
+		case key.Matches(msg, m.keys.Filter):
 			m.mode = modeFiltering
+			m.filterBefore = m.filterInput.Value()
 			m.filterInput.Focus()
 			return m, nil
-		case " ":
-			if len(m.filtered) == 0 {
+		case key.Matches(msg, m.keys.Toggle):
+			if m.cursor >= len(m.rows) {
 				return m, nil
 			}
-			m.toggleSelection(m.filtered[m.cursor].FullName)
-		case "a":
+			if row := m.rows[m.cursor]; row.header {
+				m.toggleGroupSelection(row.group)
+			} else {
+				m.toggleSelection(row.repo.FullName)
+			}
+		case key.Matches(msg, m.keys.SelectAll):
 			m.toggleSelectAll()
-		case "d":
-			if m.deleting {
-				m.status = "Delete already in progress"
+		case key.Matches(msg, m.keys.Visual):
+			if len(m.rows) == 0 {
+				return m, nil
+			}
+			m.visual = true
+			m.visualAnchor = m.cursor
+			m.visualBase = make(map[string]bool, len(m.selected))
+			for name := range m.selected {
+				m.visualBase[name] = true
+			}
+			m.extendVisual()
+		case key.Matches(msg, m.keys.Invert):
+			m.invertSelection()
+		case key.Matches(msg, m.keys.ClearAll):
+			n := len(m.selected)
+			m.selected = make(map[string]bool)
+			m.status = fmt.Sprintf("Cleared %d selections", n)
+		case key.Matches(msg, m.keys.Save):
+			if m.cfg.SelectionsDir == "" {
+				m.status = "Saved selections are disabled in this session"
+				return m, nil
+			}
+			m.mode = modeSaveSet
+			m.nameInput.Placeholder = "name for the current filter and selection; enter to save, esc to cancel"
+			m.nameInput.SetValue(m.activeSet)
+			m.nameInput.CursorEnd()
+			m.nameInput.Focus()
+			return m, nil
+		case key.Matches(msg, m.keys.Load):
+			if m.cfg.SelectionsDir == "" {
+				m.status = "Saved selections are disabled in this session"
 				return m, nil
 			}
-			queue := m.selectedRepos()
-			if len(queue) == 0 {
+			names, err := m.selectionStore().List()
+			switch {
+			case err != nil:
+				m.status = "Could not list saved selections: " + err.Error()
+				return m, nil
+			case len(names) == 0:
+				m.status = "No saved selections yet; press " + firstKey(m.keys.Save) + " to save one"
+				return m, nil
+			}
+			m.mode = modeLoadSet
+			m.nameInput.Placeholder = "name of the set to load; enter to load, esc to cancel"
+			m.nameInput.SetValue("")
+			m.nameInput.Focus()
+			m.status = "Saved selections: " + strings.Join(names, ", ")
+			return m, nil
+		case key.Matches(msg, m.keys.Export):
+			if len(m.history) == 0 && len(m.selected) == 0 {
+				m.status = "Nothing to export: select repos or run a batch first"
+				return m, nil
+			}
+			m.mode = modeExport
+			m.nameInput.Placeholder = "file to write (.md, .csv or .json); enter to export, esc to cancel"
+			m.nameInput.SetValue(time.Now().Format("fork-report-20060102-150405.md"))
+			m.nameInput.CursorEnd()
+			m.nameInput.Focus()
+			return m, nil
+		case key.Matches(msg, m.keys.AddTopic, m.keys.RemoveTopic):
+			if len(m.selected) == 0 {
 				m.status = "Nothing selected"
 				return m, nil
 			}
-			m.deleteQueue = queue
-			expect := approvalPhrase(m.userLogin)
-			m.confirmExpect = expect
-			m.confirmInput.SetValue("")
-			m.confirmInput.Placeholder = expect
-			m.confirmInput.Focus()
-			m.filterInput.Blur()
-			m.mode = modeConfirm
-			m.status = fmt.Sprintf("Confirm delete %d repos: type %q then Enter (Esc to cancel)", len(queue), expect)
+			m.mode = modeAddTopic
+			m.nameInput.Placeholder = "topic to add to the selected repos, e.g. to-review; enter to apply, esc to cancel"
+			if key.Matches(msg, m.keys.RemoveTopic) {
+				m.mode = modeRemoveTopic
+				m.nameInput.Placeholder = "topic to remove from the selected repos; enter to apply, esc to cancel"
+				if topics := m.selectedTopics(); len(topics) > 0 {
+					m.status = "Topics on the selection: " + strings.Join(topics, ", ")
+				}
+			}
+			m.nameInput.SetValue("")
+			m.nameInput.Focus()
+			return m, nil
+		case key.Matches(msg, m.keys.SelectWhere):
+			m.mode = modeSelectWhere
+			m.whereInput.SetValue(m.filterInput.Value())
+			m.whereInput.CursorEnd()
+			m.whereInput.Focus()
 			return m, nil
-		case "?":
-			m.status = "Keys: j/k move · space select · a select all · / filter · d delete · r refresh · q quit"
+		case key.Matches(msg, m.keys.Delete):
+			cmd := m.beginDelete(false)
+			return m, cmd
+		case key.Matches(msg, m.keys.ForceDelete):
+			cmd := m.beginDelete(true)
+			return m, cmd
+		case key.Matches(msg, m.keys.Quarantine):
+			m.beginQuarantine()
+		case key.Matches(msg, m.keys.PurgeView):
+			m.purgeView = !m.purgeView
+			m.cursor = 0
+			m.setFiltered(m.applyFilter(m.filterInput.Value()))
+			m.ensureVisible()
+			if m.purgeView {
+				m.status = fmt.Sprintf("Purge view: %d repos quarantined over %d days", len(m.filtered), m.cfg.PurgeAfterDays)
+			} else {
+				m.status = "Left purge view"
+			}
+		case key.Matches(msg, m.keys.GroupView):
+			cmd := m.toggleGrouped()
+			return m, cmd
+		case key.Matches(msg, m.keys.Collapse):
+			m.toggleCollapsed()
+		case key.Matches(msg, m.keys.ScanUpstream):
+			cmd := m.startUpstreamScan()
+			return m, cmd
+		case key.Matches(msg, m.keys.MakePrivate):
+			m.beginVisibility(true)
+		case key.Matches(msg, m.keys.MakePublic):
+			m.beginVisibility(false)
+		case key.Matches(msg, m.keys.Stats):
+			m.openStats()
+		case key.Matches(msg, m.keys.Help):
+			m.showHelp = true
 		}
 	}
 
 	return m, nil
 }
 
+// beginDelete queues the selection and opens the confirmation screen. Forks
+// that back open pull requests are left out unless force is set, in which
+// case an extra typed acknowledgment is required. Queued forks are scanned
+// for unique commits while the user types.
+func (m *model) beginDelete(force bool) tea.Cmd {
+	if m.running || m.graceActive {
+		m.status = m.action.title() + " already in progress"
+		return nil
+	}
+	if m.prsLoading {
+		m.status = "Still checking open pull requests; try again in a moment"
+		return nil
+	}
+	queue, blocked := m.splitOpenPRs(m.selectedRepos())
+	if force {
+		queue = append(queue, blocked...)
+		sort.Slice(queue, func(i, j int) bool { return queue[i].FullName < queue[j].FullName })
+	}
+	if len(queue) == 0 {
+		if len(blocked) > 0 {
+			m.status = fmt.Sprintf("All %d selected forks back open pull requests; press %s to delete them anyway", len(blocked), firstKey(m.keys.ForceDelete))
+			return nil
+		}
+		m.status = "Nothing selected"
+		return nil
+	}
+
+	m.action = actionDelete
+	m.queue = queue
+	expect := approvalPhrase(m.userLogin)
+	steps := []confirmStep{{
+		expect: expect,
+		prompt: fmt.Sprintf("Type %q then press Enter to delete %d repos (Esc to cancel)", expect, len(queue)),
+	}}
+	if force && len(blocked) > 0 {
+		steps = append(steps, m.openPRStep(blocked))
+	}
+	m.setConfirmStep(steps[0])
+	m.confirmNext = steps[1:]
+	m.uniqueAcked = false
+	m.filterInput.Blur()
+	m.mode = modeConfirm
+	m.status = fmt.Sprintf("Confirm delete %d repos: type %q then Enter (Esc to cancel)", len(queue), expect)
+	if !force && len(blocked) > 0 {
+		m.status += fmt.Sprintf(" · skipped %d with open PRs (%s to include)", len(blocked), firstKey(m.keys.ForceDelete))
+	}
+	return m.startUniqueScan(queue)
+}
+
+// beginQuarantine queues the selection for quarantine and opens the
+// confirmation screen. Quarantine is meant for owned repos: forks are
+// better deleted outright.
+func (m *model) beginQuarantine() {
+	if m.running || m.graceActive {
+		m.status = m.action.title() + " already in progress"
+		return
+	}
+	if m.showForks {
+		m.status = "Quarantine applies to owned repos; restart with --non-forks"
+		return
+	}
+	queue := m.selectedRepos()
+	if len(queue) == 0 {
+		m.status = "Nothing selected"
+		return
+	}
+
+	m.action = actionQuarantine
+	m.queue = queue
+	expect := approvalPhrase(m.userLogin)
+	m.setConfirmStep(confirmStep{
+		expect: expect,
+		prompt: fmt.Sprintf("Quarantine renames each repo with %q, makes it private and tags it %q.\nType %q then press Enter to quarantine %d repos (Esc to cancel)", m.cfg.QuarantinePrefix, gh.QuarantineTopic, expect, len(queue)),
+	})
+	m.confirmNext = nil
+	m.filterInput.Blur()
+	m.mode = modeConfirm
+	m.status = fmt.Sprintf("Confirm quarantine %d repos: type %q then Enter (Esc to cancel)", len(queue), expect)
+}
+
+// namePrompt reports whether the name input is open, for saving, loading,
+// exporting or tagging.
+func (m model) namePrompt() bool {
+	switch m.mode {
+	case modeSaveSet, modeLoadSet, modeExport, modeAddTopic, modeRemoveTopic:
+		return true
+	}
+	return false
+}
+
+// beginVisibility queues the selected repos that are not private (or public)
+// yet and opens the confirmation screen. Public forks are left out of a
+// make-private batch: GitHub keeps forks of public repos public.
+func (m *model) beginVisibility(private bool) {
+	if m.running || m.graceActive {
+		m.status = m.action.title() + " already in progress"
+		return
+	}
+	var queue []gh.Repo
+	forks := 0
+	for _, repo := range m.selectedRepos() {
+		switch {
+		case repo.Private == private:
+			continue
+		case private && repo.Fork:
+			forks++
+			continue
+		}
+		queue = append(queue, repo)
+	}
+	skipped := ""
+	switch {
+	case forks == 1:
+		skipped = "Skipping 1 public fork: GitHub keeps forks of public repos public"
+	case forks > 1:
+		skipped = fmt.Sprintf("Skipping %d public forks: GitHub keeps forks of public repos public", forks)
+	}
+	if len(queue) == 0 {
+		switch {
+		case forks > 0:
+			m.status = skipped
+		case len(m.selected) > 0:
+			m.status = fmt.Sprintf("Every selected repo is already %s", visibilityWord(private))
+		default:
+			m.status = "Nothing selected"
+		}
+		return
+	}
+
+	m.action = actionMakePublic
+	if private {
+		m.action = actionMakePrivate
+	}
+	m.queue = queue
+	expect := approvalPhrase(m.userLogin)
+	prompt := fmt.Sprintf("Type %q then press Enter to make %d repos %s (Esc to cancel)", expect, len(queue), visibilityWord(private))
+	if skipped != "" {
+		prompt = skipped + ".\n" + prompt
+	}
+	m.setConfirmStep(confirmStep{expect: expect, prompt: prompt})
+	m.confirmNext = nil
+	m.filterInput.Blur()
+	m.mode = modeConfirm
+	m.status = fmt.Sprintf("Confirm make %s %d repos: type %q then Enter (Esc to cancel)", visibilityWord(private), len(queue), expect)
+	if forks > 0 {
+		m.status += fmt.Sprintf(" · %d skipped", forks)
+	}
+}
+
+func visibilityWord(private bool) string {
+	if private {
+		return "private"
+	}
+	return "public"
+}
+
+// recordVisibility applies a visibility result. Forks that cannot be
+// hidden are skipped rather than failed and unselected, since retrying
+// cannot help; other failures stay selected for another try.
+func (m *model) recordVisibility(msg actionResultMsg) {
+	name := msg.repo.FullName
+	word := visibilityWord(msg.updated.Private)
+	switch {
+	case errors.Is(msg.err, gh.ErrForkVisibility):
+		m.results[name] = "skipped: fork of a public repo stays public"
+		m.status = fmt.Sprintf("Skipped %s: forks of public repos cannot be made private", name)
+		delete(m.selected, name)
+	case msg.err != nil:
+		m.results[name] = "error: " + msg.err.Error()
+		m.status = fmt.Sprintf("Failed to make %s %s", name, word)
+	case m.dryRun:
+		m.results[name] = "would make " + word
+		m.status = fmt.Sprintf("Would make %s %s (dry run)", name, word)
+		delete(m.selected, name)
+	default:
+		m.results[name] = "made " + word
+		m.status = fmt.Sprintf("Made %s %s", name, word)
+		m.replaceRepo(name, msg.updated)
+		delete(m.selected, name)
+	}
+}
+
+// startBatch runs the queued actions one after another.
+func (m *model) startBatch() tea.Cmd {
+	m.running = true
+	m.status = fmt.Sprintf("%s %d repos…", m.action.progressive(), len(m.queue))
+	return runNextCmd(m.client, m.action, m.queue[0], m.cfg.QuarantinePrefix, m.batchTopic)
+}
+
+// recordQuarantine applies a quarantine result. The repo stays listed
+// under its new name so the purge view can offer it later.
+func (m *model) recordQuarantine(msg actionResultMsg) {
+	name := msg.repo.FullName
+	switch {
+	case msg.err != nil:
+		m.results[name] = "error: " + msg.err.Error()
+		m.status = fmt.Sprintf("Failed to quarantine %s", name)
+	case m.dryRun:
+		m.results[name] = "would quarantine as " + msg.updated.FullName
+		m.status = fmt.Sprintf("Would quarantine %s (dry run)", name)
+		delete(m.selected, name)
+	default:
+		m.results[name] = "quarantined as " + msg.updated.FullName
+		m.status = fmt.Sprintf("Quarantined %s as %s", name, msg.updated.FullName)
+		m.replaceRepo(name, msg.updated)
+		delete(m.selected, name)
+	}
+}
+
+// cancelQueued drops repos from a batch that has not started yet and
+// records them as cancelled in the results and the action log.
+func (m *model) cancelQueued(repos []gh.Repo) {
+	drop := make(map[string]bool, len(repos))
+	for _, r := range repos {
+		drop[r.FullName] = true
+	}
+	kept := m.queue[:0:0]
+	for _, r := range m.queue {
+		if !drop[r.FullName] {
+			kept = append(kept, r)
+			continue
+		}
+		m.results[r.FullName] = "cancelled before execution"
+		m.recordHistory(r)
+		logLine(m.cfg.LogPath, fmt.Sprintf("%s %s -> cancelled before execution", m.action, r.FullName))
+	}
+	m.queue = kept
+	if len(kept) == 0 {
+		m.graceActive = false
+	}
+}
+
+func (m model) isQueued(fullName string) bool {
+	for _, r := range m.queue {
+		if r.FullName == fullName {
+			return true
+		}
+	}
+	return false
+}
+
+// startUniqueScan queues repos that have not been scanned yet. Scans run
+// one at a time to stay gentle on rate limits.
+func (m *model) startUniqueScan(repos []gh.Repo) tea.Cmd {
+	scanner, ok := gh.Lookup[gh.UniquenessScanner](m.client)
+	if !ok || !m.showForks {
+		return nil
+	}
+	idle := len(m.scanQueue) == 0
+	queued := make(map[string]bool, len(m.scanQueue))
+	for _, r := range m.scanQueue {
+		queued[r.FullName] = true
+	}
+	for _, r := range repos {
+		if _, done := m.uniqueness[r.FullName]; done || queued[r.FullName] {
+			continue
+		}
+		m.scanQueue = append(m.scanQueue, r)
+	}
+	if idle && len(m.scanQueue) > 0 {
+		return scanUniqueCmd(scanner, m.scanQueue[0])
+	}
+	return nil
+}
+
+// pendingScans counts queued deletes whose unique-commit scan has not
+// finished yet.
+func (m model) pendingScans() int {
+	if _, ok := gh.Lookup[gh.UniquenessScanner](m.client); !ok || !m.showForks {
+		return 0
+	}
+	n := 0
+	for _, repo := range m.queue {
+		if _, done := m.uniqueness[repo.FullName]; !done {
+			n++
+		}
+	}
+	return n
+}
+
+// uniqueWorkStep lists queued repos that hold, or may hold, commits found
+// nowhere in their parent and asks for an explicit acknowledgment.
+func (m model) uniqueWorkStep() (confirmStep, bool) {
+	var lines []string
+	for _, repo := range m.queue {
+		u, ok := m.uniqueness[repo.FullName]
+		if !ok || u.State == gh.UniqueNone {
+			continue
+		}
+		line := fmt.Sprintf("  %s: %s", repo.FullName, u)
+		switch {
+		case len(u.Refs) > 0:
+			line += " (" + strings.Join(u.Refs, ", ") + ")"
+		case u.Err != nil:
+			line += " (" + u.Err.Error() + ")"
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return confirmStep{}, false
+	}
+	expect := "delete unique work"
+	return confirmStep{
+		expect: expect,
+		prompt: fmt.Sprintf("These repos may hold work that exists nowhere else:\n%s\nType %q then press Enter to delete them anyway", strings.Join(lines, "\n"), expect),
+	}, true
+}
+
+// scanSummary counts unique-commit scan outcomes for the queued deletes.
+func (m model) scanSummary() string {
+	if _, ok := gh.Lookup[gh.UniquenessScanner](m.client); !ok || !m.showForks || len(m.queue) == 0 || m.action != actionDelete {
+		return ""
+	}
+	var safe, unique, unknown, pending int
+	for _, repo := range m.queue {
+		u, ok := m.uniqueness[repo.FullName]
+		switch {
+		case !ok:
+			pending++
+		case u.State == gh.UniqueNone:
+			safe++
+		case u.State == gh.UniqueFound:
+			unique++
+		default:
+			unknown++
+		}
+	}
+	summary := fmt.Sprintf("Unique-commit scan: %d safe · %d unique · %d unknown", safe, unique, unknown)
+	if pending > 0 {
+		summary += fmt.Sprintf(" · %d pending", pending)
+	}
+	return summary
+}
+
+// openPRStep asks for an explicit acknowledgment that deleting blocked will
+// close their pull requests upstream.
+func (m model) openPRStep(blocked []gh.Repo) confirmStep {
+	count := 0
+	var lines []string
+	for _, repo := range blocked {
+		for _, pr := range m.openPRs[repo.FullName] {
+			count++
+			lines = append(lines, fmt.Sprintf("  %s → %s#%d %s", repo.FullName, pr.Base, pr.Number, pr.Title))
+		}
+	}
+	expect := fmt.Sprintf("close %d pull requests", count)
+	return confirmStep{
+		expect: expect,
+		prompt: fmt.Sprintf("Deleting these forks closes their open pull requests upstream:\n%s\nType %q then press Enter to continue", strings.Join(lines, "\n"), expect),
+	}
+}
+
+func (m *model) setConfirmStep(step confirmStep) {
+	m.confirmExpect = step.expect
+	m.confirmPrompt = step.prompt
+	m.confirmInput.SetValue("")
+	m.confirmInput.Placeholder = step.expect
+	m.confirmInput.Focus()
+}
+
+// splitOpenPRs separates repos backing open pull requests from the rest.
+func (m model) splitOpenPRs(repos []gh.Repo) (free, blocked []gh.Repo) {
+	for _, repo := range repos {
+		if len(m.openPRs[repo.FullName]) > 0 {
+			blocked = append(blocked, repo)
+			continue
+		}
+		free = append(free, repo)
+	}
+	return free, blocked
+}
+
+// startPullScan looks up open pull requests once both the fork list and the
+// login are known. Providers without PullRequestFinder skip the check.
+func (m *model) startPullScan() tea.Cmd {
+	if !m.showForks || m.loading || m.userLogin == "" {
+		return nil
+	}
+	finder, ok := gh.Lookup[gh.PullRequestFinder](m.client)
+	if !ok {
+		return nil
+	}
+	m.prsLoading = true
+	return loadPullsCmd(finder, m.userLogin)
+}
+
+// badges returns short safety markers shown before a repo's metadata.
+func (m model) badges(repo gh.Repo) []string {
+	var out []string
+	switch n := len(m.openPRs[repo.FullName]); {
+	case n == 1:
+		out = append(out, "PR open")
+	case n > 1:
+		out = append(out, fmt.Sprintf("%d PRs open", n))
+	}
+	if u, ok := m.uniqueness[repo.FullName]; ok {
+		out = append(out, u.String())
+	}
+	out = append(out, m.upstreamBadges(repo)...)
+	if at, ok := gh.QuarantinedAt(repo); ok {
+		out = append(out, "quarantined "+at.Format("2006-01-02"))
+	}
+	if m.graceActive && m.isQueued(repo.FullName) {
+		out = append(out, fmt.Sprintf("%s in %ds", strings.ToLower(m.action.progressive()), graceSeconds(m.graceUntil)))
+	}
+	return out
+}
+
 func (m *model) toggleSelection(fullName string) {
 	if m.selected[fullName] {
 		delete(m.selected, fullName)
@@ -314,25 +1299,331 @@ func (m *model) toggleSelectAll() {
 	m.status = fmt.Sprintf("Selected %d visible repos", len(m.filtered))
 }
 
+// handleMouse moves the cursor to a clicked row, toggles the selection
+// when the checkbox is clicked, selects a range on shift-click and scrolls
+// on the wheel. Mouse input is ignored while a prompt is open.
+func (m *model) handleMouse(msg tea.MouseMsg) {
+	if m.mode != modeNormal || m.showHelp || m.showStats || m.loading || len(m.rows) == 0 {
+		return
+	}
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.scroll(-mouseWheelStep)
+		return
+	case tea.MouseButtonWheelDown:
+		m.scroll(mouseWheelStep)
+		return
+	case tea.MouseButtonLeft:
+	default:
+		return
+	}
+	if msg.Action != tea.MouseActionPress {
+		return
+	}
+	row, ok := m.rowAt(msg.Y)
+	if !ok {
+		return
+	}
+	m.visual = false
+	m.visualBase = nil
+	switch {
+	case msg.Shift:
+		m.selectRange(m.cursor, row)
+	case msg.X >= checkboxStart && msg.X < checkboxEnd && m.rows[row].header:
+		m.toggleGroupSelection(m.rows[row].group)
+	case msg.X >= checkboxStart && msg.X < checkboxEnd:
+		m.toggleSelection(m.rows[row].repo.FullName)
+	}
+	m.cursor = row
+	m.ensureVisible()
+}
+
+// Rows render as "> [x] owner/name — …": the checkbox spans these columns.
+const (
+	checkboxStart  = 2
+	checkboxEnd    = 5
+	mouseWheelStep = 3
+)
+
+// rowAt maps a screen line to an index into rows.
+func (m model) rowAt(y int) (int, bool) {
+	top := strings.Count(m.headerView(), "\n")
+	if m.err != nil {
+		top++
+	}
+	i := m.listOffset + y - top
+	if y < top || i >= m.listOffset+m.visibleRows() || i >= len(m.rows) {
+		return 0, false
+	}
+	return i, true
+}
+
+// visibleRows is the number of list rows on screen.
+func (m model) visibleRows() int {
+	if m.listHeight <= 0 || m.listHeight > len(m.rows) {
+		return len(m.rows)
+	}
+	return m.listHeight
+}
+
+// scroll moves the list window by delta rows, dragging the cursor along
+// when it would leave the window.
+func (m *model) scroll(delta int) {
+	height := m.visibleRows()
+	m.listOffset += delta
+	if maxOffset := len(m.rows) - height; m.listOffset > maxOffset {
+		m.listOffset = maxOffset
+	}
+	if m.listOffset < 0 {
+		m.listOffset = 0
+	}
+	if m.cursor < m.listOffset {
+		m.cursor = m.listOffset
+	}
+	if m.cursor >= m.listOffset+height {
+		m.cursor = m.listOffset + height - 1
+	}
+}
+
+// selectRange selects every repo row between from and to inclusive.
+func (m *model) selectRange(from, to int) {
+	if from > to {
+		from, to = to, from
+	}
+	n := 0
+	for i := from; i <= to && i < len(m.rows); i++ {
+		if !m.rows[i].header {
+			m.selected[m.rows[i].repo.FullName] = true
+			n++
+		}
+	}
+	m.status = fmt.Sprintf("Selected %d rows", n)
+}
+
+// extendVisual selects the rows between the visual anchor and the cursor on
+// top of whatever was selected when visual mode started.
+func (m *model) extendVisual() {
+	selected := make(map[string]bool, len(m.visualBase))
+	for name := range m.visualBase {
+		selected[name] = true
+	}
+	lo, hi := m.visualAnchor, m.cursor
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	n := 0
+	for i := lo; i <= hi && i < len(m.rows); i++ {
+		if !m.rows[i].header {
+			selected[m.rows[i].repo.FullName] = true
+			n++
+		}
+	}
+	m.selected = selected
+	m.status = fmt.Sprintf("-- VISUAL -- %d rows · j/k extend · v or esc to finish", n)
+}
+
+// invertSelection flips the selection of every visible repo. Hidden
+// selections are left alone.
+func (m *model) invertSelection() {
+	for _, repo := range m.filtered {
+		if m.selected[repo.FullName] {
+			delete(m.selected, repo.FullName)
+			continue
+		}
+		m.selected[repo.FullName] = true
+	}
+	m.status = fmt.Sprintf("Inverted selection: %d selected", len(m.selected))
+}
+
+// selectWhere adds every repo matching query to the selection, including
+// repos the current filter hides.
+func (m *model) selectWhere(query string) {
+	query = strings.ToLower(query)
+	match := m.matcher(query)
+	added := 0
+	for _, repo := range m.repos {
+		if m.selected[repo.FullName] || !match(repo) {
+			continue
+		}
+		m.selected[repo.FullName] = true
+		added++
+	}
+	m.status = fmt.Sprintf("Selected %d more repos matching %q", added, query)
+	if hidden := m.hiddenSelected(); hidden > 0 {
+		m.status += fmt.Sprintf(" · %d selected repos are hidden by the filter", hidden)
+	}
+}
+
+func (m model) selectionStore() selection.Store {
+	return selection.Store{Dir: m.cfg.SelectionsDir}
+}
+
+// saveSet stores the current filter and selection under name.
+func (m *model) saveSet(name string) {
+	names := make([]string, 0, len(m.selected))
+	for fullName := range m.selected {
+		names = append(names, fullName)
+	}
+	set := selection.Set{Name: name, Filter: m.filterInput.Value(), Selected: names, SavedAt: time.Now()}
+	if err := m.selectionStore().Save(set); err != nil {
+		m.status = "Could not save selection: " + err.Error()
+		return
+	}
+	m.activeSet = name
+	m.status = fmt.Sprintf("Saved %d selected and filter %q as %q", len(names), set.Filter, name)
+}
+
+// loadSet replaces the filter and selection with the saved set name.
+func (m *model) loadSet(name string) {
+	if m.loading {
+		m.status = "Still loading; try again in a moment"
+		return
+	}
+	set, err := m.selectionStore().Load(name)
+	if err != nil {
+		m.status = "Could not load selection: " + err.Error()
+		return
+	}
+	m.applySet(set)
+}
+
+// applySet restores a saved set against the loaded repos. Repos that no
+// longer exist are dropped and reported.
+func (m *model) applySet(set selection.Set) {
+	kept, missing := set.Reconcile(m.repoNames())
+	m.selected = make(map[string]bool, len(kept))
+	for _, name := range kept {
+		m.selected[name] = true
+	}
+	m.filterInput.SetValue(set.Filter)
+	m.setFiltered(m.applyFilter(set.Filter))
+	m.cursor = 0
+	m.ensureVisible()
+	m.activeSet = set.Name
+	m.status = fmt.Sprintf("Loaded selection %q: %d selected, filter %q", set.Name, len(kept), set.Filter)
+	if len(missing) > 0 {
+		m.status += " · " + missingNote(missing)
+	}
+}
+
+// recordHistory adds repo's latest result to the current batch and keeps
+// the batch on disk for `export`. Rehearsals are not kept so they never
+// replace a real batch, and neither are tag batches, which only label repos.
+func (m *model) recordHistory(repo gh.Repo) {
+	entry := report.NewEntry(repo, m.results[repo.FullName], time.Now())
+	if m.action == actionTag || m.action == actionUntag {
+		m.topicHistory = append(m.topicHistory, entry)
+		return
+	}
+	m.history = append(m.history, entry)
+	if !m.dryRun {
+		saveLastBatch(m.cfg, m.historyTitle, m.history)
+	}
+}
+
+// exportReport writes the last batch's results, or the selection when no
+// batch has run yet, to path. The extension picks the format.
+func (m *model) exportReport(path string) {
+	if path == "" {
+		m.status = "Export cancelled: no file name"
+		return
+	}
+	title := m.historyTitle
+	entries := m.history
+	if len(entries) == 0 {
+		title = "Selected repos"
+		for _, repo := range m.selectedRepos() {
+			entries = append(entries, report.NewEntry(repo, "", time.Time{}))
+		}
+	}
+	rep := report.New(title, entries, time.Now())
+	if err := writeReport(path, report.FormatForPath(path), rep); err != nil {
+		m.status = "Export failed: " + err.Error()
+		return
+	}
+	m.status = fmt.Sprintf("Exported %d repos to %s", len(entries), path)
+}
+
+// applyImport selects the repos named in an imported list and remembers
+// the entries that matched nothing.
+func (m *model) applyImport(list importedList) {
+	matched, unmatched := selection.Match(list.entries, m.repos)
+	for _, repo := range matched {
+		m.selected[repo.FullName] = true
+	}
+	m.unmatched = unmatched
+	m.status = fmt.Sprintf("Selected %d repos from %s", len(matched), list.source)
+	if len(unmatched) > 0 {
+		m.status += fmt.Sprintf(" · %d entries matched nothing: %s", len(unmatched), shortList(unmatched))
+	}
+}
+
+// reconcileSelection drops selected repos that disappeared in a refresh and
+// returns their names.
+func (m *model) reconcileSelection() []string {
+	existing := m.repoNames()
+	var missing []string
+	for name := range m.selected {
+		if !existing[name] {
+			missing = append(missing, name)
+			delete(m.selected, name)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+func (m model) repoNames() map[string]bool {
+	names := make(map[string]bool, len(m.repos))
+	for _, r := range m.repos {
+		names[r.FullName] = true
+	}
+	return names
+}
+
+// missingNote reports selected repos that no longer exist.
+func missingNote(missing []string) string {
+	return fmt.Sprintf("%d selected repos no longer exist: %s", len(missing), shortList(missing))
+}
+
+// shortList joins the first few names for a status line.
+func shortList(names []string) string {
+	if len(names) > 3 {
+		return strings.Join(names[:3], ", ") + ", …"
+	}
+	return strings.Join(names, ", ")
+}
+
+// hiddenSelected counts selected repos the current filter hides.
+func (m model) hiddenSelected() int {
+	visible := 0
+	for _, repo := range m.filtered {
+		if m.selected[repo.FullName] {
+			visible++
+		}
+	}
+	return len(m.selected) - visible
+}
+
 func (m *model) ensureVisible() {
-	if len(m.filtered) == 0 {
+	if len(m.rows) == 0 {
 		m.cursor = 0
 		m.listOffset = 0
 		return
 	}
-	if m.cursor >= len(m.filtered) {
-		m.cursor = len(m.filtered) - 1
+	if m.cursor >= len(m.rows) {
+		m.cursor = len(m.rows) - 1
 	}
 	if m.cursor < 0 {
 		m.cursor = 0
 	}
 
 	height := m.listHeight
-	if height <= 0 || height > len(m.filtered) {
-		height = len(m.filtered)
+	if height <= 0 || height > len(m.rows) {
+		height = len(m.rows)
 	}
 
-	maxOffset := len(m.filtered) - height
+	maxOffset := len(m.rows) - height
 	if maxOffset < 0 {
 		maxOffset = 0
 	}
@@ -375,59 +1666,73 @@ func (m *model) removeRepo(fullName string) {
 		filtered = append(filtered, r)
 	}
 	m.repos = filtered
-	m.filtered = m.applyFilter(m.filterInput.Value())
-	if m.cursor >= len(m.filtered) && m.cursor > 0 {
-		m.cursor = len(m.filtered) - 1
+	delete(m.index, fullName)
+	m.setFiltered(m.applyFilter(m.filterInput.Value()))
+	if m.cursor >= len(m.rows) && m.cursor > 0 {
+		m.cursor = len(m.rows) - 1
 	}
 	m.ensureVisible()
 }
 
-func (m model) applyFilter(filter string) []gh.Repo {
-	if filter == "" {
-		return append([]gh.Repo{}, m.repos...)
-	}
-	filter = strings.ToLower(filter)
-	var out []gh.Repo
-	for _, repo := range m.repos {
-		if strings.Contains(strings.ToLower(repo.FullName), filter) ||
-			strings.Contains(strings.ToLower(repo.Language), filter) ||
-			strings.Contains(strings.ToLower(repo.Owner), filter) {
-			out = append(out, repo)
+// replaceRepo swaps the listed repo fullName for repo, e.g. after a rename.
+func (m *model) replaceRepo(fullName string, repo gh.Repo) {
+	for i, r := range m.repos {
+		if r.FullName == fullName {
+			m.repos[i] = repo
 		}
 	}
-	return out
+	if m.index != nil {
+		delete(m.index, fullName)
+		m.index[repo.FullName] = indexRepo(repo)
+	}
+	m.setFiltered(m.applyFilter(m.filterInput.Value()))
+	m.ensureVisible()
 }
 
-func (m model) View() string {
-	var b strings.Builder
-
-	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("213")).Render("GitHub Fork Manager")
-	b.WriteString(title)
-	b.WriteString("\n")
+// purgeDue reports whether repo has been quarantined for at least the
+// configured number of days.
+func (m model) purgeDue(repo gh.Repo, now time.Time) bool {
+	at, ok := gh.QuarantinedAt(repo)
+	return ok && !now.Before(at.AddDate(0, 0, m.cfg.PurgeAfterDays))
+}
 
-	if m.cfg.Token == "" {
-		b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render("GITHUB_TOKEN not set. Export GITHUB_TOKEN or set token in ~/.github-fork-manager/config.json.\n\n"))
+func (m model) applyFilter(filter string) []gh.Repo {
+	repos := m.repos
+	if m.purgeView {
+		repos = nil
+		now := time.Now()
+		for _, repo := range m.repos {
+			if m.purgeDue(repo, now) {
+				repos = append(repos, repo)
+			}
+		}
 	}
-
-	stats := fmt.Sprintf("Total: %d | Filtered: %d | Selected: %d", len(m.repos), len(m.filtered), len(m.selected))
-	if m.deleting {
-		stats += fmt.Sprintf(" | Deleting %d…", len(m.deleteQueue))
+	if filter == "" {
+		return append([]gh.Repo{}, repos...)
 	}
-	b.WriteString(stats + "\n")
-	b.WriteString("Commands: j/k move · space select · a select all · / filter · d delete · r refresh · q quit\n")
-	b.WriteString("Filter: ")
-	if m.mode == modeFiltering {
-		b.WriteString(m.filterInput.View())
-	} else {
-		b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render(m.filterInput.View()))
+	return m.rankRepos(repos, filter)
+}
+
+// matcher returns the predicate select-where uses. It matches the same
+// repos as the list filter, so selecting where the current filter selects
+// exactly what the list shows.
+func (m model) matcher(filter string) func(gh.Repo) bool {
+	match := m.filterMatch(filter)
+	return func(repo gh.Repo) bool {
+		_, ok := match(repo)
+		return ok
 	}
-	b.WriteString("\n\n")
+}
 
-	if m.mode == modeConfirm {
-		b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("203")).Render("Confirmation required") + "\n")
-		b.WriteString(fmt.Sprintf("Type %q then press Enter to delete %d repos (Esc to cancel)\n", m.confirmExpect, len(m.deleteQueue)))
-		b.WriteString(m.confirmInput.View() + "\n\n")
+func (m model) View() string {
+	if m.showHelp {
+		return m.helpView()
+	}
+	if m.showStats {
+		return m.statsView()
 	}
+	var b strings.Builder
+	b.WriteString(m.headerView())
 
 	if m.loading {
 		b.WriteString("Loading forks…\n")
@@ -435,74 +1740,185 @@ func (m model) View() string {
 	}
 
 	if m.err != nil {
-		b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render("Error: "+m.err.Error()) + "\n")
+		b.WriteString(m.theme.errorText.Render("Error: "+m.err.Error()) + "\n")
 	}
 
-	if len(m.filtered) == 0 {
+	if len(m.rows) == 0 {
 		b.WriteString("No forks found.\n")
 	} else {
 		listHeight := m.listHeight
-		if listHeight <= 0 || listHeight > len(m.filtered) {
-			listHeight = len(m.filtered)
+		if listHeight <= 0 || listHeight > len(m.rows) {
+			listHeight = len(m.rows)
 		}
 		end := m.listOffset + listHeight
-		if end > len(m.filtered) {
-			end = len(m.filtered)
+		if end > len(m.rows) {
+			end = len(m.rows)
 		}
+		layout := m.layout()
+		var lines []string
 		for i := m.listOffset; i < end; i++ {
-			repo := m.filtered[i]
+			row := m.rows[i]
 			cursor := "  "
 			if i == m.cursor {
-				cursor = lipgloss.NewStyle().Foreground(lipgloss.Color("12")).Render("> ")
+				cursor = m.theme.cursor.Render("> ")
 			}
+			if row.header {
+				line := cursor + m.groupHeader(row.group)
+				if layout.columns != nil {
+					line = fit(line, layout.width)
+					line += strings.Repeat(" ", max(layout.width-lipgloss.Width(line), 0))
+				}
+				lines = append(lines, line)
+				continue
+			}
+			repo := row.repo
 			check := "[ ]"
+			pos := m.highlights(repo)
+			if layout.columns != nil {
+				if m.selected[repo.FullName] {
+					check = m.theme.selected.Render("[x]")
+				}
+				line := cursor + check + " " + m.repoColumns(layout, repo, pos)
+				lines = append(lines, line+strings.Repeat(" ", max(layout.width-layout.used(), 0)))
+				continue
+			}
+			name := m.theme.highlight(repo.FullName, pos.name)
 			if m.selected[repo.FullName] {
-				check = lipgloss.NewStyle().Foreground(lipgloss.Color("77")).Render("[x]")
+				check = m.theme.selected.Render("[x]")
+				if m.theme.mono {
+					name = m.theme.selected.Render(name)
+				}
+			}
+			meta := repoMeta(repo, m.theme, pos)
+			if badges := m.badges(repo); len(badges) > 0 {
+				meta = m.theme.badge.Render(strings.Join(badges, " · ")) + " · " + meta
 			}
-			meta := repoMeta(repo)
-			name := repo.FullName
 			if repo.HTMLURL != "" {
-				name = hyperlink(repo.HTMLURL, repo.FullName)
+				name = hyperlink(repo.HTMLURL, name)
 			}
-			line := fmt.Sprintf("%s%s %s — %s", cursor, check, name, meta)
-			b.WriteString(line + "\n")
+			if m.grouped {
+				name = "  " + name
+			}
+			lines = append(lines, fmt.Sprintf("%s%s %s — %s", cursor, check, name, meta))
+		}
+		if layout.detail {
+			lines = m.besideDetail(lines)
 		}
-		if len(m.filtered) > listHeight {
-			b.WriteString(fmt.Sprintf("Showing %d-%d of %d\n", m.listOffset+1, end, len(m.filtered)))
+		for _, line := range lines {
+			b.WriteString(strings.TrimRight(line, " ") + "\n")
+		}
+		if len(m.rows) > listHeight {
+			b.WriteString(fmt.Sprintf("Showing %d-%d of %d\n", m.listOffset+1, end, len(m.rows)))
 		}
 	}
 
 	if m.status != "" {
 		b.WriteString("\n" + m.status + "\n")
 	}
-	if len(m.deleteResults) > 0 {
+	if len(m.results) > 0 {
 		b.WriteString("\nRecent results:\n")
-		count := 0
-		for name, res := range m.deleteResults {
-			b.WriteString(fmt.Sprintf("- %s: %s\n", name, res))
-			count++
-			if count >= 5 {
+		names := make([]string, 0, len(m.results))
+		for name := range m.results {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for i, name := range names {
+			if i >= 5 {
 				break
 			}
+			b.WriteString(fmt.Sprintf("- %s: %s\n", name, m.results[name]))
 		}
 	}
 
 	return b.String()
 }
 
-func repoMeta(repo gh.Repo) string {
+// headerView renders everything above the list. Mouse handling uses its
+// height to map screen rows to repos.
+func (m model) headerView() string {
+	var b strings.Builder
+
+	title := m.theme.title.Render("GitHub Fork Manager")
+	b.WriteString(title)
+	b.WriteString("\n")
+	if m.dryRun {
+		b.WriteString(m.theme.banner.Render(" DRY RUN — nothing will be deleted ") + "\n")
+	}
+
+	if m.cfg.Token == "" {
+		b.WriteString(m.theme.errorText.Render(tokenHint(m.cfg.Provider) + "\n\n"))
+	}
+
+	stats := fmt.Sprintf("Total: %d | Filtered: %d | Selected: %d", len(m.repos), len(m.filtered), len(m.selected))
+	if hidden := m.hiddenSelected(); hidden > 0 {
+		stats += fmt.Sprintf(" (%d hidden)", hidden)
+	}
+	if m.visual {
+		stats += " | VISUAL"
+	}
+	if m.activeSet != "" {
+		stats += " | Set: " + m.activeSet
+	}
+	if m.grouped {
+		stats += " | Grouped by parent"
+	}
+	if m.purgeView {
+		stats += fmt.Sprintf(" | Purge view: quarantined over %d days", m.cfg.PurgeAfterDays)
+	}
+	if m.graceActive {
+		stats += fmt.Sprintf(" | %d %s start in %ds (%s cancel all, %s cancel one)", len(m.queue), m.action.plural(), graceSeconds(m.graceUntil), firstKey(m.keys.CancelAll), firstKey(m.keys.CancelOne))
+	}
+	if m.running {
+		stats += fmt.Sprintf(" | %s %d…", m.action.progressive(), len(m.queue))
+	}
+	b.WriteString(fit(stats, m.width) + "\n")
+	b.WriteString(fit("Commands: "+m.commandsLine(), m.width) + "\n")
+	if m.mode == modeFiltering {
+		b.WriteString(fit("Filter: "+m.filterInput.View(), m.width))
+	} else {
+		b.WriteString(fit("Filter: "+m.theme.muted.Render(m.filterInput.View()), m.width))
+	}
+	if m.mode == modeSelectWhere {
+		b.WriteString("\n" + m.whereInput.View())
+	}
+	if m.namePrompt() {
+		b.WriteString("\n" + m.nameInput.View())
+	}
+	b.WriteString("\n\n")
+
+	if m.mode == modeConfirm {
+		b.WriteString(m.theme.confirm.Render("Confirmation required") + "\n")
+		b.WriteString(m.confirmPrompt + "\n")
+		if summary := m.scanSummary(); summary != "" {
+			b.WriteString(summary + "\n")
+		}
+		b.WriteString(m.confirmInput.View() + "\n\n")
+	}
+	if layout := m.layout(); layout.columns != nil && !m.loading && len(m.rows) > 0 {
+		b.WriteString(m.theme.muted.Render(layout.columnTitles()) + "\n")
+	}
+	return b.String()
+}
+
+// repoMeta summarises a repo for its list row, highlighting filter matches
+// at pos. Monochrome themes mark the private and archived states since they
+// cannot color them.
+func repoMeta(repo gh.Repo, t theme, pos matchPositions) string {
 	var parts []string
 	if repo.Language != "" {
-		parts = append(parts, repo.Language)
+		parts = append(parts, t.highlight(repo.Language, pos.language))
 	}
 	if repo.Private {
-		parts = append(parts, "private")
+		parts = append(parts, t.state("private"))
 	}
 	if repo.Archived {
-		parts = append(parts, "archived")
+		parts = append(parts, t.state("archived"))
 	}
 	if repo.Parent != "" {
-		parts = append(parts, "parent: "+repo.Parent)
+		parts = append(parts, "parent: "+t.highlight(repo.Parent, pos.parent))
+	}
+	if topics := shownTopics(repo); len(topics) > 0 {
+		parts = append(parts, "topics: "+strings.Join(topics, ", "))
 	}
 	if repo.PushedAt.IsZero() {
 		parts = append(parts, "pushed unknown")
@@ -512,6 +1928,15 @@ func repoMeta(repo gh.Repo) string {
 	return strings.Join(parts, " · ")
 }
 
+// graceSeconds is the countdown shown for a pending delete, rounded up.
+func graceSeconds(until time.Time) int {
+	left := time.Until(until)
+	if left <= 0 {
+		return 0
+	}
+	return int((left + time.Second - 1) / time.Second)
+}
+
 func popQueue(queue []gh.Repo) []gh.Repo {
 	if len(queue) == 0 {
 		return queue
@@ -535,6 +1960,36 @@ func hyperlink(url, text string) string {
 	return fmt.Sprintf("%s]8;;%s%s\\%s%s]8;;%s\\", esc, url, esc, text, esc, esc)
 }
 
+func tokenHint(provider string) string {
+	if provider == config.ProviderGitHub || provider == "" {
+		return "GITHUB_TOKEN not set. Export GITHUB_TOKEN or set token in ~/.github-fork-manager/config.json."
+	}
+	return fmt.Sprintf("No token configured for %s. Set token in the profile in ~/.github-fork-manager/config.json.", provider)
+}
+
+// demoConfig points the app at a local fake server. Logging is disabled so
+// demo deletes never end up in the real action log.
+func demoConfig(apiBase string) config.Config {
+	return config.Config{
+		Provider: config.ProviderGitHub,
+		APIBase:  apiBase,
+		Token:    "demo",
+	}
+}
+
+// newProvider returns the hosting backend selected by cfg.Provider.
+func newProvider(cfg config.Config) (gh.Provider, error) {
+	switch cfg.Provider {
+	case config.ProviderGitHub, "":
+		return gh.New(cfg.APIBase, cfg.Token), nil
+	case config.ProviderGitea, config.ProviderForgejo:
+		return gitea.New(cfg.APIBase, cfg.Token), nil
+	case config.ProviderGitLab:
+		return gitlab.New(cfg.APIBase, cfg.Token), nil
+	}
+	return nil, fmt.Errorf("unknown provider %q", cfg.Provider)
+}
+
 func approvalPhrase(login string) string {
 	if login == "" {
 		login = "your-github-username"
@@ -558,24 +2013,132 @@ func logLine(path, line string) {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "run" {
+		os.Exit(runCommand(os.Args[2:], os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "delete" {
+		os.Exit(deleteCommand(os.Args[2:], os.Stdin, os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		os.Exit(exportCommand(os.Args[2:], os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "stats" {
+		os.Exit(statsCommand(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
 	var nonForks bool
+	var profile string
+	var demo bool
+	var dryRun bool
+	var grace time.Duration
+	var selectionName string
+	var selectFrom string
+	var noMouse bool
+	var themeName string
 	flag.BoolVar(&nonForks, "non-forks", false, "show owned non-fork repositories instead of forks")
+	flag.StringVar(&profile, "profile", "", "use the named profile from config.json")
+	flag.BoolVar(&demo, "demo", false, "run against a built-in fake GitHub with sample data (no token needed)")
+	flag.BoolVar(&dryRun, "dry-run", false, "rehearse deletes without changing anything (overrides dry_run in config)")
+	flag.DurationVar(&grace, "grace", 0, "wait this long after confirming before deleting, e.g. 30s (overrides grace_period in config)")
+	flag.StringVar(&selectionName, "selection", "", "restore the named saved filter and selection (see s/L keys)")
+	flag.StringVar(&themeName, "theme", "", "color theme: "+themeNames()+" (overrides theme in config)")
+	flag.BoolVar(&noMouse, "no-mouse", false, "leave the mouse to the terminal so text can be selected (overrides disable_mouse in config)")
+	flag.StringVar(&selectFrom, "select-from", "", "pre-select repos listed in a file: full names, URLs or IDs, one per line (- for stdin)")
 	flag.Parse()
 
-	cfg, err := config.Load()
+	var cfg config.Config
+	if demo {
+		srv := ghfake.Demo()
+		defer srv.Close()
+		cfg = demoConfig(srv.Start())
+	} else {
+		var err error
+		cfg, err = config.LoadProfile(profile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "config error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := config.EnsureLogDir(cfg.LogPath); err != nil {
+			fmt.Fprintf(os.Stderr, "log dir error: %v\n", err)
+		}
+	}
+
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "dry-run":
+			cfg.DryRun = dryRun
+		case "grace":
+			cfg.Grace = grace
+		case "no-mouse":
+			cfg.DisableMouse = noMouse
+		case "theme":
+			cfg.Theme = themeName
+		}
+	})
+
+	client, err := newProvider(cfg)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "config error: %v\n", err)
 		os.Exit(1)
 	}
-	if err := config.EnsureLogDir(cfg.LogPath); err != nil {
-		fmt.Fprintf(os.Stderr, "log dir error: %v\n", err)
+	if cfg.DryRun {
+		client = gh.DryRun(client)
 	}
 
 	showForks := !nonForks
 
-	p := tea.NewProgram(newModel(cfg, showForks))
-	if _, err := p.Run(); err != nil {
+	m := newModel(cfg, client, showForks)
+	if m.keys, err = m.keys.withOverrides(cfg.Keys); err != nil {
+		fmt.Fprintf(os.Stderr, "config error: %v\n", err)
+		os.Exit(1)
+	}
+	if m.theme, err = loadTheme(cfg.Theme); err != nil {
+		fmt.Fprintf(os.Stderr, "config error: %v\n", err)
+		os.Exit(1)
+	}
+	if selectionName != "" {
+		set, err := selection.Store{Dir: cfg.SelectionsDir}.Load(selectionName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "selection error: %v\n", err)
+			os.Exit(1)
+		}
+		m.pendingSet = &set
+	}
+	var opts []tea.ProgramOption
+	if !cfg.DisableMouse {
+		opts = append(opts, tea.WithMouseCellMotion())
+	}
+	if selectFrom != "" {
+		entries, err := readList(selectFrom, os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "select-from error: %v\n", err)
+			os.Exit(1)
+		}
+		source := selectFrom
+		if selectFrom == "-" {
+			source = "stdin"
+			// Stdin held the list, so read keys from the terminal.
+			tty, err := os.Open("/dev/tty")
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "select-from error: %v\n", err)
+				os.Exit(1)
+			}
+			defer tty.Close()
+			opts = append(opts, tea.WithInput(tty))
+		}
+		m.pendingImport = &importedList{source: source, entries: entries}
+	}
+
+	p := tea.NewProgram(m, opts...)
+	final, err := p.Run()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
 	}
+	if fm, ok := final.(model); ok && len(fm.unmatched) > 0 {
+		fmt.Fprintf(os.Stderr, "%d entries from --select-from matched no repo:\n", len(fm.unmatched))
+		for _, e := range fm.unmatched {
+			fmt.Fprintf(os.Stderr, "  %s\n", e)
+		}
+	}
 }
@@ -1,10 +1,15 @@
 package main
 
 import (
+	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/charmbracelet/bubbles/textinput"
+
+	"github.com/seeg/github-fork-manager/internal/config"
 	"github.com/seeg/github-fork-manager/internal/gh"
 )
 
@@ -26,10 +31,10 @@ func TestHyperlinkWrapsText(t *testing.T) {
 
 func TestEnsureVisibleClampsOffsets(t *testing.T) {
 	m := model{
-		filtered:   []gh.Repo{{}, {}, {}, {}, {}},
 		listHeight: 3,
 		cursor:     4,
 	}
+	m.setFiltered([]gh.Repo{{}, {}, {}, {}, {}})
 	m.ensureVisible()
 	if m.listOffset != 2 {
 		t.Fatalf("expected listOffset 2, got %d", m.listOffset)
@@ -50,3 +55,119 @@ func TestApplyFilterMatchesNameAndLanguage(t *testing.T) {
 		t.Fatalf("expected only Go repo, got %#v", got)
 	}
 }
+
+func TestApplyFilterRanksFuzzyMatches(t *testing.T) {
+	m := model{repos: []gh.Repo{
+		{FullName: "me/stoolie", PushedAt: time.Now()},
+		{FullName: "me/go-tool", Parent: "up/lib", PushedAt: time.Now().Add(-time.Hour)},
+		{FullName: "me/other", Parent: "up/toolkit", PushedAt: time.Now().Add(-2 * time.Hour)},
+	}}
+	got := m.applyFilter("tool")
+	if len(got) != 3 || got[0].FullName != "me/go-tool" || got[2].FullName != "me/stoolie" {
+		t.Fatalf("expected word-start matches first, got %v", repoNamesOf(got))
+	}
+	if got := m.applyFilter("gt lib"); len(got) != 1 || got[0].FullName != "me/go-tool" {
+		t.Fatalf("every term must match some field, got %v", repoNamesOf(got))
+	}
+
+	m.filterInput = textinput.New()
+	m.filterInput.SetValue("gt lib")
+	pos := m.highlights(m.repos[1])
+	if !reflect.DeepEqual(pos.name, []int{3, 6}) || !reflect.DeepEqual(pos.parent, []int{3, 4, 5}) {
+		t.Fatalf("unexpected highlight positions %+v", pos)
+	}
+}
+
+func TestSelectWhereMatchesTheListFilter(t *testing.T) {
+	m := model{repos: []gh.Repo{
+		{FullName: "me/stoolie", PushedAt: time.Now()},
+		{FullName: "me/go-tool", Parent: "up/lib", Fork: true, Language: "Go", PushedAt: time.Now()},
+		{FullName: "me/other", Parent: "up/toolkit", Fork: true, PushedAt: time.Now()},
+	}, selected: map[string]bool{}}
+	for _, query := range []string{"gt lib", "tool", "oth", "lang:go", "is:fork tool"} {
+		m.selected = map[string]bool{}
+		m.selectWhere(query)
+		listed := repoNamesOf(m.applyFilter(query))
+		if len(m.selected) != len(listed) {
+			t.Fatalf("%q: selected %v, list shows %v", query, m.selected, listed)
+		}
+		for _, name := range listed {
+			if !m.selected[name] {
+				t.Fatalf("%q: %s listed but not selected", query, name)
+			}
+		}
+	}
+	m.selected = map[string]bool{}
+	m.selectWhere("lang:go")
+	if len(m.selected) != 1 || !m.selected["me/go-tool"] {
+		t.Fatalf("lang:go selected %v", m.selected)
+	}
+}
+
+func TestLiveFilterDebouncesLargeLists(t *testing.T) {
+	m := newModel(config.Config{}, nil, true)
+	for i := 0; i < liveFilterLimit+1; i++ {
+		m.repos = append(m.repos, gh.Repo{FullName: fmt.Sprintf("me/repo-%d", i)})
+	}
+	m.setFiltered(m.applyFilter(""))
+	m.mode = modeFiltering
+	m.filterInput.SetValue("repo-42")
+	if cmd := m.liveFilter(); cmd == nil {
+		t.Fatalf("expected a debounce tick for a large list")
+	}
+	stale := m.filterSeq
+	m.filterInput.SetValue("repo-420")
+	m.liveFilter()
+	next, _ := m.Update(filterDebounceMsg{seq: stale})
+	if m = next.(model); len(m.filtered) != liveFilterLimit+1 {
+		t.Fatalf("stale debounce must not refilter")
+	}
+	next, _ = m.Update(filterDebounceMsg{seq: m.filterSeq})
+	if m = next.(model); len(m.filtered) == 0 || len(m.filtered) > 2 || m.filtered[0].FullName != "me/repo-420" {
+		t.Fatalf("expected refilter after debounce, got %v", repoNamesOf(m.filtered))
+	}
+}
+
+func TestLiveFilterKeepsCursorOnMatchingRepo(t *testing.T) {
+	m := newModel(config.Config{}, nil, true)
+	m.repos = []gh.Repo{{FullName: "me/alpha"}, {FullName: "me/beta"}, {FullName: "me/gamma"}}
+	m.reindex()
+	m.setFiltered(m.applyFilter(""))
+	m.cursor = 2
+	m.mode = modeFiltering
+	m.filterInput.SetValue("a")
+	m.liveFilter()
+	if repo, _ := m.cursorRepo(); repo.FullName != "me/gamma" {
+		t.Fatalf("cursor on %q, want me/gamma", repo.FullName)
+	}
+	m.filterInput.SetValue("alp")
+	m.liveFilter()
+	if m.cursor != 0 || len(m.filtered) != 1 {
+		t.Fatalf("cursor %d over %v, want the top of [me/alpha]", m.cursor, repoNamesOf(m.filtered))
+	}
+}
+
+func BenchmarkLiveFilter(b *testing.B) {
+	m := newModel(config.Config{}, nil, true)
+	langs := []string{"Go", "Python", "Rust", "TypeScript", ""}
+	for i := 0; i < 5000; i++ {
+		m.repos = append(m.repos, gh.Repo{
+			FullName: fmt.Sprintf("me/project-%d-tool", i),
+			Language: langs[i%len(langs)],
+			Parent:   fmt.Sprintf("upstream-%d/project-%d-tool", i%50, i),
+		})
+	}
+	m.reindex()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.applyFilter("pro42 ru")
+	}
+}
+
+func repoNamesOf(repos []gh.Repo) []string {
+	var out []string
+	for _, r := range repos {
+		out = append(out, r.FullName)
+	}
+	return out
+}
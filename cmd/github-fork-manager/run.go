@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/seeg/github-fork-manager/internal/config"
+	"github.com/seeg/github-fork-manager/internal/gh"
+	"github.com/seeg/github-fork-manager/internal/policy"
+	"github.com/seeg/github-fork-manager/internal/report"
+)
+
+// Exit codes of the run and delete subcommands.
+const (
+	exitOK      = 0
+	exitFailed  = 1
+	exitUsage   = 2
+	exitBreaker = 3
+)
+
+// runCommand implements `run --policy file`: it evaluates a policy against
+// the fetched repos and archives, deletes or reports the matches. It is
+// dry-run unless --dry-run=false is passed and refuses to act at all when
+// more than --max-actions changes match. Like `delete`, it skips forks that
+// back open pull requests or may hold unique commits unless --force is
+// passed.
+func runCommand(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	policyPath := fs.String("policy", "", "policy file (YAML)")
+	dryRun := fs.Bool("dry-run", true, "only report what would happen; pass --dry-run=false to act")
+	maxActions := fs.Int("max-actions", 10, "abort without changes when more archive/delete actions match")
+	force := fs.Bool("force", false, "also delete forks that back open pull requests or may hold unique commits")
+	profile := fs.String("profile", "", "use the named profile from config.json")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *policyPath == "" {
+		fmt.Fprintln(stderr, "run: --policy is required")
+		return exitUsage
+	}
+
+	pol, err := policy.Load(*policyPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "policy error: %v\n", err)
+		return exitUsage
+	}
+	cfg, err := config.LoadProfile(*profile)
+	if err != nil {
+		fmt.Fprintf(stderr, "config error: %v\n", err)
+		return exitFailed
+	}
+	client, err := newProvider(cfg)
+	if err != nil {
+		fmt.Fprintf(stderr, "config error: %v\n", err)
+		return exitFailed
+	}
+	if *dryRun {
+		client = gh.DryRun(client)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	repos, err := client.FetchRepos(ctx, pol.Forks())
+	cancel()
+	if err != nil {
+		fmt.Fprintf(stderr, "list repos: %v\n", err)
+		return exitFailed
+	}
+
+	var unique func(gh.Repo) gh.Uniqueness
+	if pol.Forks() {
+		unique = uniqueScan(client)
+	}
+	decisions := pol.Evaluate(repos, time.Now(), unique)
+
+	actions, deletes := 0, 0
+	for _, d := range decisions {
+		if d.Action != policy.ActionReport {
+			actions++
+		}
+		if d.Action == policy.ActionDelete && !d.Protected {
+			deletes++
+		}
+	}
+	if actions > *maxActions {
+		for _, d := range decisions {
+			fmt.Fprintf(stdout, "%-8s %s (%s)\n", d.Action, d.Repo.FullName, d.Rule)
+		}
+		fmt.Fprintf(stderr, "circuit breaker: %d actions exceed --max-actions=%d; nothing changed\n", actions, *maxActions)
+		logLine(cfg.LogPath, fmt.Sprintf("policy %s: circuit breaker tripped (%d actions > %d)", *policyPath, actions, *maxActions))
+		return exitBreaker
+	}
+
+	var guard deleteGuard
+	if deletes > 0 && !*force {
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		login, err := client.CurrentUser(ctx)
+		if err == nil {
+			guard, err = newDeleteGuard(ctx, client, login, unique)
+		}
+		cancel()
+		if err != nil {
+			fmt.Fprintf(stderr, "%v; nothing changed\n", err)
+			return exitFailed
+		}
+	}
+
+	failed, skipped := 0, 0
+	var history []report.Entry
+	for _, d := range decisions {
+		name := d.Repo.FullName
+		switch {
+		case d.Protected:
+			fmt.Fprintf(stdout, "protect  %s (%s)\n", name, d.Rule)
+			continue
+		case d.Action == policy.ActionReport:
+			fmt.Fprintf(stdout, "report   %s (%s)\n", name, d.Rule)
+			continue
+		}
+		if d.Action == policy.ActionDelete {
+			if reason, result := guard.check(d.Repo); reason != "" {
+				fmt.Fprintf(stdout, "skip     %s (%s) (%s; --force to delete)\n", name, d.Rule, reason)
+				logLine(cfg.LogPath, fmt.Sprintf("%s %s -> %s", d.Action, name, result))
+				history = append(history, report.NewEntry(d.Repo, result, time.Now()))
+				skipped++
+				continue
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+		var err error
+		if d.Action == policy.ActionDelete {
+			err = client.DeleteRepo(ctx, name)
+		} else {
+			err = client.ArchiveRepo(ctx, name)
+		}
+		cancel()
+		result := actionResult(d.Action, err, *dryRun)
+		if err != nil && !errors.Is(err, gh.ErrDeleteScheduled) {
+			failed++
+		}
+		fmt.Fprintf(stdout, "%-8s %s (%s) -> %s\n", d.Action, name, d.Rule, result)
+		logLine(cfg.LogPath, fmt.Sprintf("%s %s -> %s", d.Action, name, result))
+		history = append(history, report.NewEntry(d.Repo, result, time.Now()))
+	}
+	if !*dryRun {
+		saveLastBatch(cfg, "Policy run "+*policyPath, history)
+	}
+
+	if skipped > 0 {
+		fmt.Fprintf(stdout, "%d deletes skipped to keep open pull requests and unique commits; pass --force to delete them\n", skipped)
+	}
+	if *dryRun {
+		fmt.Fprintf(stdout, "dry run: %d actions planned; pass --dry-run=false to apply\n", actions-skipped)
+	}
+	if failed > 0 {
+		fmt.Fprintf(stderr, "%d of %d actions failed\n", failed, actions)
+		return exitFailed
+	}
+	return exitOK
+}
+
+// actionResult renders the outcome of an archive or delete the same way the
+// TUI records it in the action log.
+func actionResult(action policy.Action, err error, dryRun bool) string {
+	switch {
+	case err == nil && dryRun:
+		return "would " + string(action)
+	case errors.Is(err, gh.ErrDeleteScheduled):
+		return "scheduled for deletion"
+	case err != nil:
+		return "error: " + err.Error()
+	case action == policy.ActionArchive:
+		return "archived"
+	}
+	return "deleted"
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/seeg/github-fork-manager/internal/ghfake"
+)
+
+// policyEnv points config loading at srv and returns the policy and log
+// file paths.
+func policyEnv(t *testing.T, srv *ghfake.Server, policyYAML string) (string, string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GITHUB_TOKEN", "token")
+	t.Setenv("GITHUB_API_BASE", srv.Start())
+	t.Cleanup(srv.Close)
+	p := filepath.Join(home, "policy.yaml")
+	if err := os.WriteFile(p, []byte(policyYAML), 0o644); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	return p, filepath.Join(home, ".github-fork-manager", "actions.log")
+}
+
+func staleServer() *ghfake.Server {
+	srv := ghfake.New("me")
+	old := time.Now().AddDate(-2, 0, 0)
+	srv.AddRepo(ghfake.Repo{Owner: "up", Name: "lib"})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "stale-a", Parent: "up/lib", PushedAt: old})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "stale-b", Parent: "up/lib", PushedAt: old})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "keep-me", Parent: "up/lib", PushedAt: old})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "fresh", Parent: "up/lib", PushedAt: time.Now()})
+	return srv
+}
+
+const stalePolicy = `
+protected: ["me/keep-*"]
+rules:
+  - name: stale
+    action: delete
+    match:
+      pushed_older_than_days: 365
+      no_unique_commits: true
+`
+
+func TestRunPolicyDefaultsToDryRun(t *testing.T) {
+	srv := staleServer()
+	p, logPath := policyEnv(t, srv, stalePolicy)
+	var out, errOut bytes.Buffer
+
+	if code := runCommand([]string{"--policy", p}, &out, &errOut); code != exitOK {
+		t.Fatalf("exit %d: %s", code, errOut.String())
+	}
+	if _, ok := srv.Repo("me/stale-a"); !ok {
+		t.Fatalf("dry run must not delete")
+	}
+	if !strings.Contains(out.String(), "me/stale-a (stale) -> would delete") || !strings.Contains(out.String(), "protect  me/keep-me") {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+	logData, _ := os.ReadFile(logPath)
+	if !strings.Contains(string(logData), "delete me/stale-b -> would delete") {
+		t.Fatalf("expected dry run in action log, got %q", logData)
+	}
+}
+
+func TestRunPolicyCircuitBreaker(t *testing.T) {
+	srv := staleServer()
+	p, _ := policyEnv(t, srv, stalePolicy)
+	var out, errOut bytes.Buffer
+
+	code := runCommand([]string{"--policy", p, "--dry-run=false", "--max-actions", "1"}, &out, &errOut)
+	if code != exitBreaker {
+		t.Fatalf("expected breaker exit, got %d", code)
+	}
+	if _, ok := srv.Repo("me/stale-a"); !ok {
+		t.Fatalf("breaker must stop all actions")
+	}
+}
+
+func TestRunPolicyApplies(t *testing.T) {
+	srv := staleServer()
+	p, logPath := policyEnv(t, srv, stalePolicy)
+	var out, errOut bytes.Buffer
+
+	if code := runCommand([]string{"--policy", p, "--dry-run=false"}, &out, &errOut); code != exitOK {
+		t.Fatalf("exit %d: %s", code, errOut.String())
+	}
+	for _, name := range []string{"me/stale-a", "me/stale-b"} {
+		if _, ok := srv.Repo(name); ok {
+			t.Fatalf("expected %s deleted", name)
+		}
+	}
+	for _, name := range []string{"me/keep-me", "me/fresh"} {
+		if _, ok := srv.Repo(name); !ok {
+			t.Fatalf("expected %s kept", name)
+		}
+	}
+	logData, _ := os.ReadFile(logPath)
+	if !strings.Contains(string(logData), "delete me/stale-a -> deleted") {
+		t.Fatalf("expected delete in action log, got %q", logData)
+	}
+}
+
+func TestRunPolicySkipsForksWithOpenPullRequests(t *testing.T) {
+	srv := staleServer()
+	srv.AddPullRequest(ghfake.PullRequest{Base: "up/lib", Title: "Fix parser", Author: "me", Head: "me/stale-a"})
+	p, logPath := policyEnv(t, srv, `
+rules:
+  - name: stale
+    action: delete
+    match:
+      pushed_older_than_days: 365
+`)
+	var out, errOut bytes.Buffer
+
+	if code := runCommand([]string{"--policy", p, "--dry-run=false"}, &out, &errOut); code != exitOK {
+		t.Fatalf("exit %d: %s", code, errOut.String())
+	}
+	if _, ok := srv.Repo("me/stale-a"); !ok {
+		t.Fatalf("expected me/stale-a kept for its open pull request")
+	}
+	if _, ok := srv.Repo("me/stale-b"); ok {
+		t.Fatalf("expected me/stale-b deleted")
+	}
+	if srv.PullRequests()[0].Closed {
+		t.Fatalf("expected the pull request to stay open")
+	}
+	if !strings.Contains(out.String(), "skip     me/stale-a (stale) (backs 1 open pull requests; --force to delete)") {
+		t.Fatalf("expected skip line, got:\n%s", out.String())
+	}
+	logData, _ := os.ReadFile(logPath)
+	if !strings.Contains(string(logData), "delete me/stale-a -> skipped: open pull requests") {
+		t.Fatalf("expected skip in action log, got %q", logData)
+	}
+
+	out.Reset()
+	if code := runCommand([]string{"--policy", p, "--dry-run=false", "--force"}, &out, &errOut); code != exitOK {
+		t.Fatalf("exit %d: %s", code, errOut.String())
+	}
+	if _, ok := srv.Repo("me/stale-a"); ok {
+		t.Fatalf("expected --force to delete me/stale-a")
+	}
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/seeg/github-fork-manager/internal/config"
+	"github.com/seeg/github-fork-manager/internal/gh"
+)
+
+// statsTopN is how many of the largest repos the stats list.
+const statsTopN = 10
+
+// statsBarWidth is the length of the longest bar in a section.
+const statsBarWidth = 30
+
+// statBucket is one line of a stats section. filter is the filter term
+// that lists the bucket's repos.
+type statBucket struct {
+	label  string
+	count  int
+	value  string
+	filter string
+}
+
+type statSection struct {
+	title   string
+	buckets []statBucket
+}
+
+// repoStats summarises a set of repos for the stats screen.
+type repoStats struct {
+	total    int
+	size     int
+	sections []statSection
+}
+
+// computeStats counts repos by language, visibility, state, kind and age,
+// and lists the topN largest.
+func computeStats(repos []gh.Repo, now time.Time, topN int) repoStats {
+	st := repoStats{total: len(repos)}
+	langs := make(map[string]int)
+	var private, archived, forks int
+	ages := make(map[string]int)
+	for _, repo := range repos {
+		st.size += repo.Size
+		langs[repo.Language]++
+		if repo.Private {
+			private++
+		}
+		if repo.Archived {
+			archived++
+		}
+		if repo.Fork {
+			forks++
+		}
+		ages[ageBucket(repo.PushedAt, now)]++
+	}
+
+	lang := statSection{title: "Language"}
+	for name, n := range langs {
+		label, value := name, langValue(name)
+		if name == "" {
+			label = "(none)"
+		}
+		lang.buckets = append(lang.buckets, statBucket{label: label, count: n, filter: "lang:" + value})
+	}
+	sort.Slice(lang.buckets, func(i, j int) bool {
+		a, b := lang.buckets[i], lang.buckets[j]
+		if a.count != b.count {
+			return a.count > b.count
+		}
+		return strings.ToLower(a.label) < strings.ToLower(b.label)
+	})
+
+	age := statSection{title: "Last push"}
+	for _, bucket := range append(append([]string(nil), ageBuckets...), ageUnknown) {
+		if bucket == ageUnknown && ages[bucket] == 0 {
+			continue
+		}
+		age.buckets = append(age.buckets, statBucket{label: bucket, count: ages[bucket], filter: "pushed:" + bucket})
+	}
+
+	largest := statSection{title: "Largest"}
+	bySize := append([]gh.Repo(nil), repos...)
+	sort.SliceStable(bySize, func(i, j int) bool { return bySize[i].Size > bySize[j].Size })
+	for _, repo := range bySize[:min(topN, len(bySize))] {
+		largest.buckets = append(largest.buckets, statBucket{label: repo.FullName, count: repo.Size, value: formatSize(repo.Size), filter: "repo:" + strings.ToLower(repo.FullName)})
+	}
+
+	st.sections = []statSection{
+		lang,
+		{title: "Visibility", buckets: []statBucket{
+			{label: "public", count: len(repos) - private, filter: "is:public"},
+			{label: "private", count: private, filter: "is:private"},
+		}},
+		{title: "State", buckets: []statBucket{
+			{label: "active", count: len(repos) - archived, filter: "is:active"},
+			{label: "archived", count: archived, filter: "is:archived"},
+		}},
+		{title: "Kind", buckets: []statBucket{
+			{label: "fork", count: forks, filter: "is:fork"},
+			{label: "source", count: len(repos) - forks, filter: "is:source"},
+		}},
+		age,
+		largest,
+	}
+	return st
+}
+
+// buckets lists every bucket in display order; the stats cursor indexes it.
+func (st repoStats) buckets() []statBucket {
+	var out []statBucket
+	for _, s := range st.sections {
+		out = append(out, s.buckets...)
+	}
+	return out
+}
+
+// render draws the stats as text with ASCII bars, marking the bucket at
+// cursor; a negative cursor marks none.
+func (st repoStats) render(t theme, cursor int) string {
+	var b strings.Builder
+	noun := "repos"
+	if st.total == 1 {
+		noun = "repo"
+	}
+	b.WriteString(fmt.Sprintf("%d %s · %s in total\n", st.total, noun, formatSize(st.size)))
+	width := 0
+	for _, bucket := range st.buckets() {
+		width = max(width, len([]rune(bucket.label)))
+	}
+	width = min(width, 40)
+	i := 0
+	for _, s := range st.sections {
+		b.WriteString("\n" + t.title.Render(s.title) + "\n")
+		most := 0
+		for _, bucket := range s.buckets {
+			most = max(most, bucket.count)
+		}
+		for _, bucket := range s.buckets {
+			mark := "  "
+			if i == cursor {
+				mark = t.cursor.Render("> ")
+			}
+			value := bucket.value
+			if value == "" {
+				value = fmt.Sprint(bucket.count)
+			}
+			bar := ""
+			if most > 0 {
+				bar = strings.Repeat("#", (bucket.count*statsBarWidth+most-1)/most)
+			}
+			label := pad(runewidth.Truncate(bucket.label, width, "…"), width, false)
+			b.WriteString(strings.TrimRight(fmt.Sprintf("%s%s  %9s  %s", mark, label, value, bar), " ") + "\n")
+			i++
+		}
+	}
+	return b.String()
+}
+
+// openStats snapshots the stats of the listed repos.
+func (m *model) openStats() {
+	m.stats = computeStats(m.filtered, time.Now(), statsTopN)
+	m.statsCursor = 0
+	m.showStats = true
+}
+
+// jumpToBucket closes the stats and narrows the current filter to the
+// bucket under the cursor.
+func (m *model) jumpToBucket() tea.Cmd {
+	buckets := m.stats.buckets()
+	m.showStats = false
+	if m.statsCursor >= len(buckets) {
+		return nil
+	}
+	bucket := buckets[m.statsCursor]
+	if !contains(strings.Fields(m.filterInput.Value()), bucket.filter) {
+		m.filterInput.SetValue(strings.TrimSpace(m.filterInput.Value() + " " + bucket.filter))
+	}
+	m.filterInput.CursorEnd()
+	m.cursor = 0
+	m.setFiltered(m.applyFilter(m.filterInput.Value()))
+	m.ensureVisible()
+	m.status = fmt.Sprintf("%s: %s · %d shown", m.statsSection(m.statsCursor), bucket.label, len(m.filtered))
+	return m.upstreamFilterCmd()
+}
+
+// statsSection is the title of the section holding bucket i.
+func (m model) statsSection(i int) string {
+	for _, s := range m.stats.sections {
+		if i < len(s.buckets) {
+			return s.title
+		}
+		i -= len(s.buckets)
+	}
+	return ""
+}
+
+func (m model) statsView() string {
+	var b strings.Builder
+	b.WriteString(m.theme.title.Render("Repo stats") + "\n")
+	b.WriteString(m.theme.muted.Render(fmt.Sprintf("%s/%s move · %s show these repos · %s close", firstKey(m.keys.Up), firstKey(m.keys.Down), firstKey(m.keys.Apply), firstKey(m.keys.Cancel))) + "\n\n")
+	b.WriteString(m.stats.render(m.theme, m.statsCursor))
+	return b.String()
+}
+
+// statsCommand implements `stats`: it prints the stats screen for the
+// fetched repos, optionally narrowed by a filter.
+func statsCommand(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	nonForks := fs.Bool("non-forks", false, "summarise owned non-fork repos instead of forks")
+	filter := fs.String("filter", "", "only count repos matching this filter, as typed after / in the TUI")
+	top := fs.Int("top", statsTopN, "how many of the largest repos to list")
+	profile := fs.String("profile", "", "use the named profile from config.json")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if bad := unknownQualifier(*filter); bad != "" {
+		fmt.Fprintf(stderr, "stats: unknown filter term %q\n", bad)
+		return exitUsage
+	}
+	if *top < 0 {
+		fmt.Fprintf(stderr, "stats: --top must be 0 or more, got %d\n", *top)
+		return exitUsage
+	}
+
+	cfg, err := config.LoadProfile(*profile)
+	if err != nil {
+		fmt.Fprintf(stderr, "config error: %v\n", err)
+		return exitFailed
+	}
+	client, err := newProvider(cfg)
+	if err != nil {
+		fmt.Fprintf(stderr, "config error: %v\n", err)
+		return exitFailed
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	repos, err := client.FetchRepos(ctx, !*nonForks)
+	cancel()
+	if err != nil {
+		fmt.Fprintf(stderr, "list repos: %v\n", err)
+		return exitFailed
+	}
+	if *filter != "" {
+		repos = model{repos: repos}.applyFilter(*filter)
+	}
+	fmt.Fprint(stdout, computeStats(repos, time.Now(), *top).render(theme{}, -1))
+	return exitOK
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/seeg/github-fork-manager/internal/ghfake"
+)
+
+// statsServer seeds forks in every push-age bucket, pushed relative to now
+// so the buckets stay put.
+func statsServer() *ghfake.Server {
+	srv := ghfake.New("me")
+	ago := func(months int) time.Time { return time.Now().AddDate(0, -months, 0) }
+	srv.AddRepo(ghfake.Repo{Owner: "up", Name: "lib"})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "fresh", Parent: "up/lib", Language: "Go", Size: 120, PushedAt: ago(1)})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "recent", Parent: "up/lib", Language: "Go", Size: 4096, PushedAt: ago(6)})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "notebooks", Parent: "up/lib", Language: "Jupyter Notebook", Private: true, Size: 250_000, PushedAt: ago(20)})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "ancient", Parent: "up/lib", Archived: true, Size: 12, PushedAt: ago(50)})
+	return srv
+}
+
+func TestFlowStatsJumpToBucket(t *testing.T) {
+	m := newFakeModel(t, statsServer())
+	m = drive(m, m.Init())
+	m = press(m, runes("S"))
+	assertGolden(t, "stats", normalizeView(m.View()))
+
+	// Language: Go, (none), Jupyter Notebook.
+	m = press(m, keyDown, keyDown, keyEnter)
+	if m.showStats || m.filterInput.Value() != "lang:jupyter-notebook" {
+		t.Fatalf("expected a language filter, got %q (stats open: %v)", m.filterInput.Value(), m.showStats)
+	}
+	if got := repoNamesOf(m.filtered); len(got) != 1 || got[0] != "me/notebooks" {
+		t.Fatalf("filtered %v, want [me/notebooks]", got)
+	}
+
+	// Stats now cover the filtered list, and jumping again adds to the
+	// filter rather than replacing it.
+	m = press(m, runes("S"), keyDown, keyDown, keyEnter)
+	if m.filterInput.Value() != "lang:jupyter-notebook is:private" || len(m.filtered) != 1 {
+		t.Fatalf("filter %q over %v", m.filterInput.Value(), repoNamesOf(m.filtered))
+	}
+}
+
+func TestStatsLargestJumpsToThatRepoOnly(t *testing.T) {
+	srv := ghfake.New("me")
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "tool", Size: 900})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "tool-extras", Size: 10})
+	srv.AddRepo(ghfake.Repo{Owner: "me", Name: "mytool", Size: 5})
+	m := newFakeModel(t, srv)
+	m.showForks = false
+	m = drive(m, m.Init())
+	m.openStats()
+	for i, b := range m.stats.buckets() {
+		if b.label == "me/tool" {
+			m.statsCursor = i
+		}
+	}
+	m.jumpToBucket()
+	if got := repoNamesOf(m.filtered); m.filterInput.Value() != "repo:me/tool" || len(got) != 1 || got[0] != "me/tool" {
+		t.Fatalf("filter %q listed %v", m.filterInput.Value(), got)
+	}
+}
+
+func TestStatsCommand(t *testing.T) {
+	srv := statsServer()
+	policyEnv(t, srv, "")
+	var out, errOut bytes.Buffer
+	if code := statsCommand([]string{"--top", "2"}, &out, &errOut); code != exitOK {
+		t.Fatalf("exit %d: %s", code, errOut.String())
+	}
+	for _, want := range []string{"4 repos · 248.3 MB in total", "0-3m", "3y+", "me/notebooks", "244.1 MB"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("stats missing %q:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "me/ancient") {
+		t.Fatalf("--top 2 listed a third repo:\n%s", out.String())
+	}
+
+	out.Reset()
+	if code := statsCommand([]string{"--filter", "is:archived"}, &out, &errOut); code != exitOK || !strings.HasPrefix(out.String(), "1 repo ") {
+		t.Fatalf("filtered stats: exit %d:\n%s", code, out.String())
+	}
+	if code := statsCommand([]string{"--filter", "pushed:ages"}, &out, &errOut); code != exitUsage {
+		t.Fatalf("expected usage error for an unknown term, got %d", code)
+	}
+	if code := statsCommand([]string{"--top", "-1"}, &out, &errOut); code != exitUsage {
+		t.Fatalf("expected usage error for a negative --top, got %d", code)
+	}
+}
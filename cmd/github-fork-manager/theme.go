@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme names accepted in config.json and by --theme.
+const (
+	themeDefault      = "default"
+	themeLight        = "light-terminal"
+	themeHighContrast = "high-contrast"
+	themeColorblind   = "colorblind-safe"
+	themeMonochrome   = "monochrome"
+)
+
+// theme holds the styles used by the views. Monochrome themes carry no
+// colors, so states that other themes only color get a marker or bold
+// text instead.
+type theme struct {
+	name      string
+	mono      bool
+	title     lipgloss.Style
+	cursor    lipgloss.Style
+	selected  lipgloss.Style
+	badge     lipgloss.Style
+	errorText lipgloss.Style
+	muted     lipgloss.Style
+	banner    lipgloss.Style
+	confirm   lipgloss.Style
+	key       lipgloss.Style
+	match     lipgloss.Style
+}
+
+// palette lists the colors of a theme, in the order of the theme fields.
+type palette struct {
+	title, cursor, selected, badge, errorText, muted, bannerFG, bannerBG, confirm, key string
+}
+
+var palettes = map[string]palette{
+	themeDefault:      {"213", "12", "77", "208", "196", "241", "0", "220", "203", "212"},
+	themeLight:        {"90", "25", "28", "130", "160", "243", "231", "130", "124", "90"},
+	themeHighContrast: {"15", "14", "10", "11", "9", "7", "0", "11", "9", "14"},
+	// Okabe-Ito colors: blue instead of green for selections, orange and
+	// vermillion for warnings.
+	themeColorblind: {"175", "74", "32", "214", "166", "246", "0", "214", "166", "74"},
+}
+
+// themeNames lists the accepted theme names for error messages.
+func themeNames() string {
+	return strings.Join([]string{themeDefault, themeLight, themeHighContrast, themeColorblind, themeMonochrome}, ", ")
+}
+
+// loadTheme returns the named theme. An empty name picks monochrome when
+// NO_COLOR is set and the default palette otherwise; an explicit name
+// wins over NO_COLOR.
+func loadTheme(name string) (theme, error) {
+	if name == "" {
+		name = themeDefault
+		if os.Getenv("NO_COLOR") != "" {
+			name = themeMonochrome
+		}
+	}
+	if name == themeMonochrome {
+		bold := lipgloss.NewStyle().Bold(true)
+		return theme{
+			name:      name,
+			mono:      true,
+			title:     bold,
+			cursor:    bold,
+			selected:  bold,
+			badge:     lipgloss.NewStyle(),
+			errorText: bold,
+			muted:     lipgloss.NewStyle(),
+			banner:    bold.Reverse(true),
+			confirm:   bold,
+			key:       bold,
+			match:     bold.Underline(true),
+		}, nil
+	}
+	p, ok := palettes[name]
+	if !ok {
+		return theme{}, fmt.Errorf("unknown theme %q (want one of %s)", name, themeNames())
+	}
+	fg := func(c string) lipgloss.Style { return lipgloss.NewStyle().Foreground(lipgloss.Color(c)) }
+	t := theme{
+		name:      name,
+		title:     fg(p.title).Bold(true),
+		cursor:    fg(p.cursor),
+		selected:  fg(p.selected),
+		badge:     fg(p.badge),
+		errorText: fg(p.errorText),
+		muted:     fg(p.muted),
+		banner:    fg(p.bannerFG).Background(lipgloss.Color(p.bannerBG)).Bold(true),
+		confirm:   fg(p.confirm).Bold(true),
+		key:       fg(p.key),
+		match:     fg(p.key).Bold(true).Underline(true),
+	}
+	if name == themeHighContrast {
+		t.selected = t.selected.Bold(true)
+		t.errorText = t.errorText.Bold(true)
+	}
+	return t, nil
+}
+
+// defaultTheme is the theme used before config is applied, e.g. in tests.
+func defaultTheme() theme {
+	t, _ := loadTheme(themeDefault)
+	return t
+}
+
+// state renders a repo state such as "private" in the list metadata.
+func (t theme) state(label string) string {
+	if t.mono {
+		return lipgloss.NewStyle().Bold(true).Render("[" + label + "]")
+	}
+	return label
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadTheme(t *testing.T) {
+	for _, name := range []string{"", themeDefault, themeLight, themeHighContrast, themeColorblind, themeMonochrome} {
+		if _, err := loadTheme(name); err != nil {
+			t.Fatalf("theme %q: %v", name, err)
+		}
+	}
+	if _, err := loadTheme("solarized"); err == nil || !strings.Contains(err.Error(), themeColorblind) {
+		t.Fatalf("expected unknown theme error listing names, got %v", err)
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if th, _ := loadTheme(""); !th.mono {
+		t.Fatalf("NO_COLOR should select the monochrome theme")
+	}
+	if th, _ := loadTheme(themeLight); th.mono || th.name != themeLight {
+		t.Fatalf("an explicit theme should win over NO_COLOR, got %+v", th.name)
+	}
+}
+
+func TestMonochromeMarksStates(t *testing.T) {
+	m := newFakeModel(t, flowServer())
+	m.theme, _ = loadTheme(themeMonochrome)
+	m = drive(m, m.Init())
+	m = press(m, keySpace)
+	view := normalizeView(m.View())
+	for _, want := range []string{"[x] me/go-tool", "Python · [private]", "Go · [archived]"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("monochrome view missing %q:\n%s", want, view)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/seeg/github-fork-manager/internal/gh"
+	"github.com/seeg/github-fork-manager/internal/report"
+)
+
+// shownTopics are the topics listed for repo. Quarantine markers are left
+// out since the quarantine badge already says it.
+func shownTopics(repo gh.Repo) []string {
+	var out []string
+	for _, t := range repo.Topics {
+		if t == gh.QuarantineTopic || strings.HasPrefix(t, gh.QuarantineTopic+"-") {
+			continue
+		}
+		out = append(out, t)
+	}
+	return out
+}
+
+// selectedTopics lists the topics found on the selected repos.
+func (m model) selectedTopics() []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, repo := range m.selectedRepos() {
+		for _, t := range shownTopics(repo) {
+			if !seen[t] {
+				seen[t] = true
+				out = append(out, t)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// beginTopic adds topic to, or removes it from, every selected repo. Topics
+// only label repos, so the batch starts without a typed confirmation and
+// leaves history, and the title export gives it, to the last real batch.
+func (m *model) beginTopic(topic string, add bool) tea.Cmd {
+	topic = strings.ToLower(topic)
+	switch {
+	case m.running || m.graceActive:
+		m.status = m.action.title() + " already in progress"
+		return nil
+	case topic == "":
+		m.status = "Cancelled: no topic"
+		return nil
+	case !gh.ValidTopic(topic):
+		m.status = fmt.Sprintf("%q is not a valid topic: use up to 50 lowercase letters, digits and hyphens", topic)
+		return nil
+	}
+	queue := m.selectedRepos()
+	if len(queue) == 0 {
+		m.status = "Nothing selected"
+		return nil
+	}
+	m.action = actionUntag
+	if add {
+		m.action = actionTag
+	}
+	m.batchTopic = topic
+	m.queue = queue
+	m.topicHistory = nil
+	return m.startBatch()
+}
+
+// recordTopic applies a tag or untag result. Repos stay selected so a
+// follow-up action can use the same selection.
+func (m *model) recordTopic(msg actionResultMsg) {
+	name := msg.repo.FullName
+	verb := "tagged"
+	if msg.action == actionUntag {
+		verb = "untagged"
+	}
+	switch {
+	case msg.err != nil:
+		m.results[name] = "error: " + msg.err.Error()
+		m.status = fmt.Sprintf("Failed to %s %s", msg.action, name)
+	case msg.unchanged && msg.action == actionUntag:
+		m.results[name] = "unchanged: no topic " + m.batchTopic
+	case msg.unchanged:
+		m.results[name] = "unchanged: already tagged " + m.batchTopic
+	case m.dryRun:
+		m.results[name] = fmt.Sprintf("would be %s: %s", verb, m.batchTopic)
+		m.status = fmt.Sprintf("Would %s %s with %s (dry run)", msg.action, name, m.batchTopic)
+	default:
+		m.results[name] = fmt.Sprintf("%s: %s", verb, m.batchTopic)
+		m.status = fmt.Sprintf("%s %s", strings.ToUpper(verb[:1])+verb[1:], name)
+		m.replaceRepo(name, msg.updated)
+	}
+}
+
+// topicSummary counts the outcomes of the finished batch, e.g. "Tag keep:
+// 3 tagged · 1 unchanged".
+func (m model) topicSummary() string {
+	counts := make(map[string]int)
+	var order []string
+	for _, e := range m.topicHistory {
+		outcome := report.Outcome(e.Result)
+		if counts[outcome] == 0 {
+			order = append(order, outcome)
+		}
+		counts[outcome]++
+	}
+	parts := make([]string, len(order))
+	for i, o := range order {
+		parts[i] = fmt.Sprintf("%d %s", counts[o], o)
+	}
+	return fmt.Sprintf("%s %s: %s", m.action.title(), m.batchTopic, strings.Join(parts, " · "))
+}
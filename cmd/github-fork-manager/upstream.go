@@ -0,0 +1,326 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/seeg/github-fork-manager/internal/config"
+	"github.com/seeg/github-fork-manager/internal/gh"
+)
+
+// upstreamFilterPrefix starts filter terms that match scanned upstream
+// states, e.g. "upstream:deleted,archived".
+const upstreamFilterPrefix = "upstream:"
+
+// upstreamAny matches forks with any upstream problem.
+const upstreamAny = "any"
+
+// knownParent is a fork's parent as an upstream scan last found it.
+// GitHub only ever reports a parent's current name, so a later scan finds
+// renames and transfers by comparing against this record.
+type knownParent struct {
+	ID       int64  `json:"id"`
+	FullName string `json:"full_name"`
+}
+
+type upstreamMsg struct {
+	repo   gh.Repo
+	result gh.Upstream
+}
+
+func scanUpstreamCmd(scanner gh.UpstreamScanner, repo gh.Repo) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		return upstreamMsg{repo: repo, result: scanner.ScanUpstream(ctx, repo)}
+	}
+}
+
+// startUpstreamScan checks the parent and source of every listed fork, one
+// at a time to stay gentle on rate limits.
+func (m *model) startUpstreamScan() tea.Cmd {
+	if len(m.upstreamQueue) > 0 {
+		m.status = fmt.Sprintf("Upstream scan running: %d forks left", len(m.upstreamQueue))
+		return nil
+	}
+	scanner, ok := gh.Lookup[gh.UpstreamScanner](m.client)
+	if !ok {
+		m.status = "Upstream checks are not supported by this provider"
+		return nil
+	}
+	cmd := m.queueUpstreamScan(scanner, false)
+	if cmd == nil {
+		m.status = "No forks to check"
+		return nil
+	}
+	m.status = fmt.Sprintf("Checking the upstream of %d forks…", len(m.upstreamQueue))
+	return cmd
+}
+
+// queueUpstreamScan queues every fork, or with onlyNew those no scan has
+// covered yet, with the parent recorded for it. It returns the scan of the
+// first one, or nil when there is nothing to scan.
+func (m *model) queueUpstreamScan(scanner gh.UpstreamScanner, onlyNew bool) tea.Cmd {
+	if m.knownParents == nil {
+		m.knownParents = loadKnownParents(m.cfg)
+	}
+	for _, repo := range m.repos {
+		if _, scanned := m.upstream[repo.FullName]; !repo.Fork || (onlyNew && scanned) {
+			continue
+		}
+		if known, ok := m.knownParents[repo.ID]; ok {
+			repo.Parent, repo.ParentID = known.FullName, known.ID
+		}
+		m.upstreamQueue = append(m.upstreamQueue, repo)
+	}
+	if len(m.upstreamQueue) == 0 {
+		return nil
+	}
+	return scanUpstreamCmd(scanner, m.upstreamQueue[0])
+}
+
+// recordUpstream stores one scan result and scans the next fork. The
+// parent it found replaces the listed one and is recorded for the next
+// scan. The list is refiltered once the scan is done so upstream filters
+// and the grouped view pick it up.
+func (m *model) recordUpstream(msg upstreamMsg) tea.Cmd {
+	if m.upstream == nil {
+		m.upstream = make(map[string]gh.Upstream)
+	}
+	m.upstream[msg.repo.FullName] = msg.result
+	if m.knownParents == nil {
+		m.knownParents = make(map[int64]knownParent)
+	}
+	if u := msg.result; u.Err == nil && u.ParentID != 0 {
+		m.knownParents[msg.repo.ID] = knownParent{ID: u.ParentID, FullName: u.Parent}
+		m.setParent(msg.repo.FullName, u)
+	}
+	m.upstreamQueue = popQueue(m.upstreamQueue)
+	if len(m.upstreamQueue) > 0 {
+		if scanner, ok := gh.Lookup[gh.UpstreamScanner](m.client); ok {
+			m.status = fmt.Sprintf("Checking upstreams: %d forks left", len(m.upstreamQueue))
+			return scanUpstreamCmd(scanner, m.upstreamQueue[0])
+		}
+		m.upstreamQueue = nil
+	}
+	m.upstreamScanned = true
+	saveKnownParents(m.cfg, m.knownParents)
+	m.filtered = m.applyFilter(m.filterInput.Value())
+	m.relayout()
+	m.status = "Upstream scan done: " + m.upstreamSummary()
+	return nil
+}
+
+// setParent puts the scanned parent and source on the listed fork. The
+// rows are rebuilt once the scan is done.
+func (m *model) setParent(fullName string, u gh.Upstream) {
+	for i, repo := range m.repos {
+		if repo.FullName != fullName {
+			continue
+		}
+		repo.Parent, repo.ParentID, repo.Source = u.Parent, u.ParentID, u.Source
+		m.repos[i] = repo
+		if m.index != nil {
+			m.index[fullName] = indexRepo(repo)
+		}
+	}
+}
+
+// applyScannedParents puts the parents found by upstream scans back on
+// reloaded forks, which GitHub lists without them, so a refresh keeps the
+// rows in step with the scan results.
+func (m *model) applyScannedParents() {
+	for i, repo := range m.repos {
+		if !repo.Fork || repo.Parent != "" {
+			continue
+		}
+		if u, ok := m.upstream[repo.FullName]; ok && u.Err == nil && u.ParentID != 0 {
+			repo.Parent, repo.ParentID, repo.Source = u.Parent, u.ParentID, u.Source
+		} else if known, ok := m.knownParents[repo.ID]; ok {
+			repo.Parent, repo.ParentID = known.FullName, known.ID
+		}
+		m.repos[i] = repo
+	}
+}
+
+// knownParentsPath is where scanned parents are kept between runs. It sits
+// next to the action log; without a log nothing is kept.
+func knownParentsPath(cfg config.Config) string {
+	if cfg.LogPath == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(cfg.LogPath), "parents.json")
+}
+
+// loadKnownParents reads the recorded parents by fork ID. A missing or
+// unreadable file only means renames from before it cannot be detected.
+func loadKnownParents(cfg config.Config) map[int64]knownParent {
+	known := make(map[int64]knownParent)
+	path := knownParentsPath(cfg)
+	if path == "" {
+		return known
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return known
+	}
+	_ = json.Unmarshal(data, &known)
+	return known
+}
+
+// saveKnownParents records known, replacing the file atomically. Failures
+// are ignored like action log failures.
+func saveKnownParents(cfg config.Config, known map[int64]knownParent) {
+	path := knownParentsPath(cfg)
+	if path == "" || len(known) == 0 {
+		return
+	}
+	data, err := json.MarshalIndent(known, "", "  ")
+	if err != nil {
+		return
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(dir, ".parents.json-*")
+	if err != nil {
+		return
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	_ = os.Rename(tmp.Name(), path)
+}
+
+// upstreamSummary counts scanned forks per state, e.g. "2 deleted · 1
+// archived".
+func (m model) upstreamSummary() string {
+	var parts []string
+	for _, state := range gh.UpstreamStates {
+		n := 0
+		for _, u := range m.upstream {
+			if u.Has(state) {
+				n++
+			}
+		}
+		if n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, state))
+		}
+	}
+	failed := 0
+	for _, u := range m.upstream {
+		if u.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		parts = append(parts, fmt.Sprintf("%d unknown", failed))
+	}
+	if len(parts) == 0 {
+		return "every upstream looks fine"
+	}
+	return strings.Join(parts, " · ")
+}
+
+// upstreamBadges describes the scanned upstream of repo for its row.
+func (m model) upstreamBadges(repo gh.Repo) []string {
+	u, ok := m.upstream[repo.FullName]
+	if !ok {
+		return nil
+	}
+	var out []string
+	for _, state := range u.States {
+		switch state {
+		case gh.UpstreamRenamed, gh.UpstreamTransferred:
+			out = append(out, fmt.Sprintf("upstream %s → %s", state, u.Parent))
+		default:
+			out = append(out, "upstream "+state)
+		}
+	}
+	return out
+}
+
+// splitUpstreamTerms separates upstream:state terms from the rest of the
+// filter text. Unknown states are returned so they can be reported.
+func splitUpstreamTerms(filter string) (text string, states, unknown []string) {
+	if !strings.Contains(strings.ToLower(filter), upstreamFilterPrefix) {
+		return filter, nil, nil
+	}
+	var rest []string
+	for _, field := range strings.Fields(filter) {
+		value, ok := strings.CutPrefix(strings.ToLower(field), upstreamFilterPrefix)
+		if !ok {
+			rest = append(rest, field)
+			continue
+		}
+		for _, state := range strings.Split(value, ",") {
+			if state == "" {
+				continue
+			}
+			if !validUpstreamState(state) {
+				unknown = append(unknown, state)
+			}
+			states = append(states, state)
+		}
+	}
+	return strings.Join(rest, " "), states, unknown
+}
+
+func validUpstreamState(state string) bool {
+	if state == upstreamAny {
+		return true
+	}
+	for _, s := range gh.UpstreamStates {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesUpstream reports whether the scanned upstream of repo is in any
+// of states. Unscanned forks never match.
+func (m model) matchesUpstream(repo gh.Repo, states []string) bool {
+	u, ok := m.upstream[repo.FullName]
+	if !ok {
+		return false
+	}
+	for _, state := range states {
+		if (state == upstreamAny && len(u.States) > 0) || u.Has(state) {
+			return true
+		}
+	}
+	return false
+}
+
+// upstreamFilterCmd starts a scan when the applied filter asks for upstream
+// states that have not been scanned yet.
+func (m *model) upstreamFilterCmd() tea.Cmd {
+	_, states, unknown := splitUpstreamTerms(m.filterInput.Value())
+	if len(unknown) > 0 {
+		m.status = fmt.Sprintf("Unknown upstream state %q; use %s or %s", unknown[0], strings.Join(gh.UpstreamStates, ", "), upstreamAny)
+		return nil
+	}
+	if len(states) == 0 || m.upstreamScanned {
+		return nil
+	}
+	cmd := m.startUpstreamScan()
+	if cmd != nil {
+		m.status += "; the filter updates when done"
+	}
+	return cmd
+}
@@ -7,24 +7,80 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 // Config holds app configuration.
 type Config struct {
-	Token   string `json:"token"`
-	APIBase string `json:"api_base"`
-	LogPath string `json:"log_path"`
+	Token    string             `json:"token"`
+	APIBase  string             `json:"api_base"`
+	LogPath  string             `json:"log_path"`
+	Provider string             `json:"provider"`
+	Profiles map[string]Profile `json:"profiles"`
+	// DryRun makes every mutating call a no-op by default; --dry-run
+	// overrides it either way.
+	DryRun bool `json:"dry_run"`
+	// GracePeriod delays confirmed deletes (e.g. "30s") so they can still
+	// be cancelled; empty or "0" runs them immediately. Load parses it
+	// into Grace.
+	GracePeriod string        `json:"grace_period"`
+	Grace       time.Duration `json:"-"`
+	// QuarantinePrefix is prepended to the name of quarantined repos;
+	// PurgeAfterDays is how long they stay quarantined before the purge
+	// view offers them for deletion.
+	QuarantinePrefix string `json:"quarantine_prefix"`
+	PurgeAfterDays   int    `json:"purge_after_days"`
+	// SelectionsDir holds saved filter and selection sets.
+	SelectionsDir string `json:"selections_dir"`
+	// DisableMouse leaves mouse events to the terminal, for terminals
+	// where mouse capture gets in the way of selecting text.
+	DisableMouse bool `json:"disable_mouse"`
+	// Keys overrides key bindings by action name, e.g.
+	// {"delete": ["D"], "force_delete": ["ctrl+d"]}.
+	Keys map[string][]string `json:"keys"`
+	// Theme names the color palette; empty picks the default, or
+	// monochrome when NO_COLOR is set.
+	Theme string `json:"theme"`
 }
 
+// Profile holds connection settings for a named account or host. Selecting
+// a profile replaces the top-level provider, token and api_base. GitLab
+// profiles default api_base to gitlab.com.
+type Profile struct {
+	Provider string `json:"provider"`
+	Token    string `json:"token"`
+	APIBase  string `json:"api_base"`
+}
+
+// Supported hosting providers.
+const (
+	ProviderGitHub  = "github"
+	ProviderGitea   = "gitea"
+	ProviderForgejo = "forgejo"
+	ProviderGitLab  = "gitlab"
+)
+
 const (
-	defaultAPIBase = "https://api.github.com"
+	defaultAPIBase          = "https://api.github.com"
+	defaultGitLabAPIBase    = "https://gitlab.com/api/v4"
+	DefaultQuarantinePrefix = "zz-retired-"
+	DefaultPurgeAfterDays   = 30
 )
 
 // Load returns config from file plus environment overrides.
 func Load() (Config, error) {
+	return LoadProfile("")
+}
+
+// LoadProfile is Load with the named profile applied; an empty name uses
+// the top-level settings.
+func LoadProfile(name string) (Config, error) {
 	cfg := Config{
-		APIBase: defaultAPIBase,
-		LogPath: filepath.Join(defaultConfigDir(), "actions.log"),
+		APIBase:          defaultAPIBase,
+		LogPath:          filepath.Join(defaultConfigDir(), "actions.log"),
+		QuarantinePrefix: DefaultQuarantinePrefix,
+		PurgeAfterDays:   DefaultPurgeAfterDays,
+		SelectionsDir:    filepath.Join(defaultConfigDir(), "selections"),
 	}
 
 	if data, err := os.ReadFile(configPath()); err == nil {
@@ -35,20 +91,62 @@ func Load() (Config, error) {
 		return cfg, fmt.Errorf("read config: %w", err)
 	}
 
+	if name != "" {
+		profile, ok := cfg.Profiles[name]
+		if !ok {
+			return cfg, fmt.Errorf("unknown profile %q", name)
+		}
+		cfg.Provider = profile.Provider
+		cfg.Token = profile.Token
+		cfg.APIBase = profile.APIBase
+	}
+
 	// Apply defaults if missing.
-	if cfg.APIBase == "" {
-		cfg.APIBase = defaultAPIBase
+	if cfg.Provider == "" {
+		cfg.Provider = ProviderGitHub
 	}
 	if cfg.LogPath == "" {
 		cfg.LogPath = filepath.Join(defaultConfigDir(), "actions.log")
 	}
+	if cfg.SelectionsDir == "" {
+		cfg.SelectionsDir = filepath.Join(defaultConfigDir(), "selections")
+	}
 
-	// Environment overrides.
-	if envToken := os.Getenv("GITHUB_TOKEN"); envToken != "" {
-		cfg.Token = envToken
+	switch cfg.Provider {
+	case ProviderGitHub:
+		if cfg.APIBase == "" {
+			cfg.APIBase = defaultAPIBase
+		}
+		// Environment overrides only target GitHub; other providers take
+		// their settings from the config file.
+		if envToken := os.Getenv("GITHUB_TOKEN"); envToken != "" {
+			cfg.Token = envToken
+		}
+		if envBase := os.Getenv("GITHUB_API_BASE"); envBase != "" {
+			cfg.APIBase = envBase
+		}
+	case ProviderGitLab:
+		if cfg.APIBase == "" {
+			cfg.APIBase = defaultGitLabAPIBase
+		}
+	case ProviderGitea, ProviderForgejo:
+		if cfg.APIBase == "" {
+			return cfg, fmt.Errorf("provider %s requires api_base", cfg.Provider)
+		}
+	default:
+		return cfg, fmt.Errorf("unknown provider %q", cfg.Provider)
 	}
-	if envBase := os.Getenv("GITHUB_API_BASE"); envBase != "" {
-		cfg.APIBase = envBase
+
+	if cfg.GracePeriod != "" && cfg.GracePeriod != "0" {
+		grace, err := time.ParseDuration(cfg.GracePeriod)
+		if err != nil || grace < 0 {
+			return cfg, fmt.Errorf("grace_period: invalid duration %q", cfg.GracePeriod)
+		}
+		cfg.Grace = grace
+	}
+
+	if cfg.PurgeAfterDays < 0 {
+		return cfg, fmt.Errorf("purge_after_days: must not be negative, got %d", cfg.PurgeAfterDays)
 	}
 
 	expandedLog, err := expandPath(cfg.LogPath)
@@ -56,6 +154,11 @@ func Load() (Config, error) {
 		return cfg, fmt.Errorf("log path: %w", err)
 	}
 	cfg.LogPath = expandedLog
+	expandedSelections, err := expandPath(cfg.SelectionsDir)
+	if err != nil {
+		return cfg, fmt.Errorf("selections dir: %w", err)
+	}
+	cfg.SelectionsDir = expandedSelections
 
 	return cfg, nil
 }
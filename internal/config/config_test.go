@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestLoadPrefersEnvOverridesAndExpandsPaths(t *testing.T) {
@@ -57,4 +58,65 @@ func TestLoadDefaultsWhenNoFile(t *testing.T) {
 	if cfg.LogPath != defLog {
 		t.Fatalf("expected default log path %q, got %q", defLog, cfg.LogPath)
 	}
+	if want := filepath.Join(tmp, ".github-fork-manager", "selections"); cfg.SelectionsDir != want {
+		t.Fatalf("expected default selections dir %q, got %q", want, cfg.SelectionsDir)
+	}
+	if cfg.QuarantinePrefix != "zz-retired-" || cfg.PurgeAfterDays != 30 {
+		t.Fatalf("unexpected quarantine defaults: %q, %d days", cfg.QuarantinePrefix, cfg.PurgeAfterDays)
+	}
+}
+
+func TestLoadProfileSelectsProvider(t *testing.T) {
+	tmp := t.TempDir()
+	homeCfgDir := filepath.Join(tmp, ".github-fork-manager")
+	if err := os.MkdirAll(homeCfgDir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	err := os.WriteFile(filepath.Join(homeCfgDir, "config.json"), []byte(`{
+		"token": "ghtoken",
+		"dry_run": true,
+		"grace_period": "30s",
+		"profiles": {
+			"mirrors": {"provider": "forgejo", "token": "fjtoken", "api_base": "https://git.example.com/api/v1"},
+			"broken": {"provider": "gitea"},
+			"lab": {"provider": "gitlab", "token": "gltoken"}
+		}
+	}`), 0o644)
+	if err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	t.Setenv("HOME", tmp)
+	t.Setenv("GITHUB_TOKEN", "envtoken")
+	t.Setenv("GITHUB_API_BASE", "")
+
+	cfg, err := LoadProfile("mirrors")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Provider != ProviderForgejo || cfg.Token != "fjtoken" || cfg.APIBase != "https://git.example.com/api/v1" {
+		t.Fatalf("profile not applied: %#v", cfg)
+	}
+
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("load default: %v", err)
+	}
+	if cfg.Provider != ProviderGitHub || cfg.Token != "envtoken" || !cfg.DryRun || cfg.Grace != 30*time.Second {
+		t.Fatalf("expected github defaults with env token, got %#v", cfg)
+	}
+
+	cfg, err = LoadProfile("lab")
+	if err != nil {
+		t.Fatalf("load gitlab: %v", err)
+	}
+	if cfg.APIBase != "https://gitlab.com/api/v4" || cfg.Token != "gltoken" {
+		t.Fatalf("expected gitlab defaults, got %#v", cfg)
+	}
+
+	if _, err := LoadProfile("broken"); err == nil {
+		t.Fatalf("expected error for gitea profile without api_base")
+	}
+	if _, err := LoadProfile("missing"); err == nil {
+		t.Fatalf("expected error for unknown profile")
+	}
 }
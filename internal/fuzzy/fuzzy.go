@@ -0,0 +1,133 @@
+// Package fuzzy scores subsequence matches the way fzf does: every pattern
+// character must appear in order, and matches earn more for being
+// consecutive or for starting a word.
+package fuzzy
+
+import "unicode"
+
+// Scoring weights, loosely following fzf's v1 algorithm.
+const (
+	scoreMatch       = 16
+	bonusBoundary    = 8
+	bonusFirstChar   = 8
+	bonusConsecutive = 6
+	penaltyGapStart  = 3
+	penaltyGapExtend = 1
+	bonusExactLength = 10
+)
+
+// Text is a string prepared for repeated matching: lowered once, with its
+// word starts worked out up front.
+type Text struct {
+	lower    []rune
+	boundary []bool
+}
+
+// Prepare lowers text and marks its word starts.
+func Prepare(text string) Text {
+	runes := []rune(text)
+	t := Text{lower: make([]rune, len(runes)), boundary: make([]bool, len(runes))}
+	for i, r := range runes {
+		t.lower[i] = unicode.ToLower(r)
+		t.boundary[i] = boundary(runes, i)
+	}
+	return t
+}
+
+// Match reports whether pattern is a subsequence of text, ignoring case,
+// and scores it. Positions are the rune indexes of the matched characters
+// in text. pattern must already be lowercase; an empty pattern matches
+// with score 0.
+func Match(pattern, text string) (score int, positions []int, ok bool) {
+	return Prepare(text).Match([]rune(pattern))
+}
+
+// Match is the package-level Match against a prepared text.
+func (t Text) Match(pat []rune) (score int, positions []int, ok bool) {
+	if len(pat) == 0 {
+		return 0, nil, true
+	}
+	runes := t.lower
+	if len(pat) > len(runes) {
+		return 0, nil, false
+	}
+
+	// Forward pass: find where the leftmost complete match ends.
+	pi, end := 0, -1
+	for i, r := range runes {
+		if r == pat[pi] {
+			pi++
+			if pi == len(pat) {
+				end = i
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+	// Backward pass from there: the shortest window holding the match.
+	pi = len(pat) - 1
+	start := end
+	for i := end; i >= 0; i-- {
+		if runes[i] == pat[pi] {
+			pi--
+			if pi < 0 {
+				start = i
+				break
+			}
+		}
+	}
+
+	// Score the window left to right, preferring word starts.
+	positions = make([]int, 0, len(pat))
+	pi = 0
+	gap := 0
+	prevMatched := false
+	for i := start; i <= end && pi < len(pat); i++ {
+		if runes[i] != pat[pi] {
+			if gap == 0 {
+				score -= penaltyGapStart
+			} else {
+				score -= penaltyGapExtend
+			}
+			gap++
+			prevMatched = false
+			continue
+		}
+		score += scoreMatch
+		if t.boundary[i] {
+			score += bonusBoundary
+			if pi == 0 {
+				score += bonusFirstChar
+			}
+		}
+		if prevMatched {
+			score += bonusConsecutive
+		}
+		positions = append(positions, i)
+		prevMatched = true
+		gap = 0
+		pi++
+	}
+	if len(pat) == len(runes) {
+		score += bonusExactLength
+	}
+	return score, positions, true
+}
+
+// boundary reports whether runes[i] starts a word: the first character,
+// one after a separator, or an upper-case letter after a lower-case one.
+func boundary(runes []rune, i int) bool {
+	if i == 0 {
+		return true
+	}
+	prev, cur := runes[i-1], runes[i]
+	switch {
+	case !unicode.IsLetter(prev) && !unicode.IsDigit(prev):
+		return true
+	case unicode.IsLower(prev) && unicode.IsUpper(cur):
+		return true
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package fuzzy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchFindsSubsequence(t *testing.T) {
+	score, pos, ok := Match("gtl", "me/go-tool")
+	if !ok {
+		t.Fatalf("expected match")
+	}
+	if want := []int{3, 6, 9}; !reflect.DeepEqual(pos, want) {
+		t.Fatalf("positions = %v, want %v", pos, want)
+	}
+	if score <= 0 {
+		t.Fatalf("expected positive score, got %d", score)
+	}
+	if _, _, ok := Match("xyz", "me/go-tool"); ok {
+		t.Fatalf("unexpected match")
+	}
+	if _, _, ok := Match("lg", "me/go-tool"); ok {
+		t.Fatalf("order must matter")
+	}
+}
+
+func TestMatchIgnoresCase(t *testing.T) {
+	if _, pos, ok := Match("bt", "charmbracelet/BubbleTea"); !ok || len(pos) != 2 {
+		t.Fatalf("expected case-insensitive match, got %v %v", pos, ok)
+	}
+}
+
+func TestMatchRanksWordStartsAndRuns(t *testing.T) {
+	rank := func(pattern, text string) int {
+		score, _, ok := Match(pattern, text)
+		if !ok {
+			t.Fatalf("%q should match %q", pattern, text)
+		}
+		return score
+	}
+	if rank("tool", "me/go-tool") <= rank("tool", "me/stoolie") {
+		t.Fatalf("a match at a word start should outrank one inside a word")
+	}
+	if rank("go", "me/go-tool") <= rank("go", "me/gallery-old") {
+		t.Fatalf("consecutive matches should outrank scattered ones")
+	}
+	if rank("go", "Go") <= rank("go", "Google") {
+		t.Fatalf("an exact match should outrank a prefix")
+	}
+}
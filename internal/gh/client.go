@@ -1,6 +1,7 @@
 package gh
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -23,10 +24,18 @@ type Repo struct {
 	Size          int
 	Language      string
 	DefaultBranch string
-	Parent        string
-	PushedAt      time.Time
-	HTMLURL       string
-	SSHURL        string
+	// Parent is the full name of the repo this one was forked from, and
+	// ParentID its ID. GitHub listings leave both empty; GetRepo fills
+	// them in for forks, with the parent's current name.
+	Parent   string
+	ParentID int64
+	PushedAt time.Time
+	HTMLURL  string
+	SSHURL   string
+	Topics   []string
+	// Source is the root of the fork network. Listings leave it empty;
+	// GetRepo fills it in for forks.
+	Source string
 }
 
 // Client is a minimal GitHub client.
@@ -128,7 +137,7 @@ func (c Client) DeleteRepo(ctx context.Context, fullName string) error {
 		return nil
 	}
 	if resp.StatusCode == http.StatusNotFound {
-		return fmt.Errorf("not found: %s", fullName)
+		return fmt.Errorf("%w: %s", ErrNotFound, fullName)
 	}
 	if resp.StatusCode == http.StatusForbidden {
 		return fmt.Errorf("forbidden: %s", strings.TrimSpace(string(body)))
@@ -137,6 +146,84 @@ func (c Client) DeleteRepo(ctx context.Context, fullName string) error {
 	return fmt.Errorf("delete %s: %s: %s", fullName, resp.Status, strings.TrimSpace(string(body)))
 }
 
+// GetRepo fetches a single repository by full name.
+func (c Client) GetRepo(ctx context.Context, fullName string) (Repo, error) {
+	payload, err := c.getRepo(ctx, fullName)
+	if err != nil {
+		return Repo{}, err
+	}
+	return mapRepo(payload), nil
+}
+
+// getRepo is GetRepo before mapping, for callers that need more of the
+// parent than its name.
+func (c Client) getRepo(ctx context.Context, fullName string) (apiRepo, error) {
+	if c.Token == "" {
+		return apiRepo{}, errors.New("GITHUB_TOKEN not set")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/repos/%s", c.BaseURL, fullName), nil)
+	if err != nil {
+		return apiRepo{}, err
+	}
+	c.applyHeaders(req)
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return apiRepo{}, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return apiRepo{}, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return apiRepo{}, fmt.Errorf("%w: %s", ErrNotFound, fullName)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return apiRepo{}, fmt.Errorf("get %s: %s: %s", fullName, resp.Status, strings.TrimSpace(string(body)))
+	}
+	var payload apiRepo
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return apiRepo{}, err
+	}
+	return payload, nil
+}
+
+// ArchiveRepo marks a repository as archived (read-only).
+func (c Client) ArchiveRepo(ctx context.Context, fullName string) error {
+	if c.Token == "" {
+		return errors.New("GITHUB_TOKEN not set")
+	}
+	url := fmt.Sprintf("%s/repos/%s", c.BaseURL, fullName)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, url, strings.NewReader(`{"archived":true}`))
+	if err != nil {
+		return err
+	}
+	c.applyHeaders(req)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s", ErrNotFound, fullName)
+	}
+	if resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("forbidden: %s", strings.TrimSpace(string(body)))
+	}
+
+	return fmt.Errorf("archive %s: %s: %s", fullName, resp.Status, strings.TrimSpace(string(body)))
+}
+
 // CurrentUser fetches the login of the authenticated user.
 func (c Client) CurrentUser(ctx context.Context) (string, error) {
 	if c.Token == "" {
@@ -168,6 +255,38 @@ func (c Client) CurrentUser(ctx context.Context) (string, error) {
 	return payload.Login, nil
 }
 
+// send issues an API request against BaseURL with an optional JSON payload
+// and returns the status code and body. Callers interpret the status.
+func (c Client) send(ctx context.Context, method, path string, payload any) (int, []byte, error) {
+	var reqBody io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return 0, nil, err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, reqBody)
+	if err != nil {
+		return 0, nil, err
+	}
+	c.applyHeaders(req)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return 0, nil, err
+	}
+	return resp.StatusCode, body, nil
+}
+
 func (c Client) applyHeaders(req *http.Request) {
 	req.Header.Set("Accept", "application/vnd.github+json")
 	if c.Token != "" {
@@ -190,17 +309,31 @@ type apiRepo struct {
 	Owner         struct {
 		Login string `json:"login"`
 	} `json:"owner"`
-	Parent *struct {
-		FullName string `json:"full_name"`
-	} `json:"parent"`
-	HTMLURL string `json:"html_url"`
-	SSHURL  string `json:"ssh_url"`
+	Parent  *apiParent `json:"parent"`
+	Source  *apiParent `json:"source"`
+	HTMLURL string     `json:"html_url"`
+	SSHURL  string     `json:"ssh_url"`
+	Topics  []string   `json:"topics"`
+}
+
+// apiParent is the part of the parent and source repos that single-repo
+// responses embed and the scans use.
+type apiParent struct {
+	ID       int64  `json:"id"`
+	FullName string `json:"full_name"`
+	Private  bool   `json:"private"`
+	Archived bool   `json:"archived"`
 }
 
 func mapRepo(r apiRepo) Repo {
-	parent := ""
+	parent, parentID := "", int64(0)
 	if r.Parent != nil {
-		parent = r.Parent.FullName
+		parent, parentID = r.Parent.FullName, r.Parent.ID
+	}
+
+	source := ""
+	if r.Source != nil {
+		source = r.Source.FullName
 	}
 
 	return Repo{
@@ -215,8 +348,11 @@ func mapRepo(r apiRepo) Repo {
 		Language:      r.Language,
 		DefaultBranch: r.DefaultBranch,
 		Parent:        parent,
+		ParentID:      parentID,
+		Source:        source,
 		PushedAt:      r.PushedAt,
 		HTMLURL:       r.HTMLURL,
 		SSHURL:        r.SSHURL,
+		Topics:        r.Topics,
 	}
 }
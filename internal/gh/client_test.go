@@ -3,8 +3,11 @@ package gh
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 )
@@ -95,3 +98,333 @@ func TestCurrentUser(t *testing.T) {
 		t.Fatalf("expected login octocat, got %s", login)
 	}
 }
+
+func TestGetRepoMapsParent(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/me/forked" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"full_name":"me/forked","fork":true,"owner":{"login":"me"},"parent":{"full_name":"up/forked"}}`))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, "token")
+	ctx := context.Background()
+	repo, err := client.GetRepo(ctx, "me/forked")
+	if err != nil {
+		t.Fatalf("get repo: %v", err)
+	}
+	if repo.Parent != "up/forked" || repo.Owner != "me" {
+		t.Fatalf("unexpected repo: %#v", repo)
+	}
+	if _, err := client.GetRepo(ctx, "me/missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+}
+
+func TestScanUpstreamComparesWithRecordedParent(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/me/forked" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"full_name":"me/forked","fork":true,"parent":{"id":7,"full_name":"up/renamed","archived":true}}`))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, "token")
+	cases := []struct {
+		name     string
+		parent   string
+		parentID int64
+		want     string
+	}{
+		{"never scanned", "", 0, "archived"},
+		{"same name", "up/renamed", 7, "archived"},
+		{"renamed", "up/forked", 7, "archived, renamed"},
+		{"renamed before ids were recorded", "up/forked", 0, "archived, renamed"},
+		{"transferred", "old/forked", 7, "archived, transferred"},
+		{"reparented", "up/forked", 3, "deleted, archived"},
+	}
+	for _, tc := range cases {
+		got := client.ScanUpstream(context.Background(), Repo{FullName: "me/forked", Fork: true, Parent: tc.parent, ParentID: tc.parentID})
+		if got.Err != nil {
+			t.Fatalf("%s: %v", tc.name, got.Err)
+		}
+		if got.String() != tc.want || got.Parent != "up/renamed" || got.ParentID != 7 {
+			t.Fatalf("%s: got %q with parent %q (%d)", tc.name, got, got.Parent, got.ParentID)
+		}
+	}
+}
+
+func TestArchiveRepoSendsPatch(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch || r.URL.Path != "/repos/me/old" {
+			http.NotFound(w, r)
+			return
+		}
+		var body map[string]bool
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || !body["archived"] {
+			t.Errorf("expected archived=true body, got %v (%v)", body, err)
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"full_name":"me/old","archived":true}`))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, "token")
+	if err := client.ArchiveRepo(context.Background(), "me/old"); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	if err := client.ArchiveRepo(context.Background(), "me/missing"); err == nil {
+		t.Fatalf("expected not found error")
+	}
+}
+
+func TestSetVisibilitySendsBothFields(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			http.NotFound(w, r)
+			return
+		}
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode: %v", err)
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Path {
+		case "/repos/me/site":
+			if body["private"] != true || body["visibility"] != "private" {
+				t.Errorf("expected private in both fields, got %v", body)
+				http.Error(w, "unexpected request", http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"full_name":"me/site","private":true}`))
+		case "/repos/me/docs":
+			if body["private"] != false || body["visibility"] != "public" {
+				t.Errorf("expected public in both fields, got %v", body)
+				http.Error(w, "unexpected request", http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"full_name":"me/docs"}`))
+		case "/repos/me/fork":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"message":"Validation Failed: forks of public repositories cannot be made private"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, "token")
+	ctx := context.Background()
+	if err := client.SetVisibility(ctx, "me/site", true); err != nil {
+		t.Fatalf("make private: %v", err)
+	}
+	if err := client.SetVisibility(ctx, "me/docs", false); err != nil {
+		t.Fatalf("make public: %v", err)
+	}
+	if err := client.SetVisibility(ctx, "me/fork", true); !errors.Is(err, ErrForkVisibility) {
+		t.Fatalf("expected ErrForkVisibility, got %v", err)
+	}
+	if err := client.SetVisibility(ctx, "me/missing", true); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestOpenPullRequestsByForkResolvesHeads(t *testing.T) {
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/search/issues":
+			if q := r.URL.Query().Get("q"); q != "is:pr is:open author:me" {
+				t.Errorf("unexpected query %q", q)
+				http.Error(w, "unexpected request", http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"total_count":2,"items":[
+				{"number":7,"repository_url":"` + ts.URL + `/repos/up/lib"},
+				{"number":9,"repository_url":"` + ts.URL + `/repos/up/gone"}
+			]}`))
+		case "/repos/up/lib/pulls/7":
+			w.Write([]byte(`{"number":7,"title":"Fix","head":{"repo":{"full_name":"me/lib"}}}`))
+		case "/repos/up/gone/pulls/9":
+			w.Write([]byte(`{"number":9,"title":"Orphan","head":{"repo":null}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, "token")
+	prs, err := client.OpenPullRequestsByFork(context.Background(), "me")
+	if err != nil {
+		t.Fatalf("open prs: %v", err)
+	}
+	if len(prs) != 1 || len(prs["me/lib"]) != 1 || prs["me/lib"][0].Base != "up/lib" {
+		t.Fatalf("unexpected prs: %#v", prs)
+	}
+}
+
+func TestScanUniquenessComparesRefsWithParent(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/me/lib":
+			w.Write([]byte(`{"full_name":"me/lib","parent":{"full_name":"up/lib"}}`))
+		case "/repos/up/lib":
+			w.Write([]byte(`{"full_name":"up/lib","default_branch":"trunk"}`))
+		case "/repos/me/lib/branches":
+			if r.URL.Query().Get("page") != "1" {
+				w.Write([]byte(`[]`))
+				return
+			}
+			w.Write([]byte(`[{"name":"main","commit":{"sha":"aaa"}},{"name":"wip","commit":{"sha":"bbb"}},{"name":"next","commit":{"sha":"ccc"}}]`))
+		case "/repos/me/lib/tags":
+			if r.URL.Query().Get("page") != "1" {
+				w.Write([]byte(`[]`))
+				return
+			}
+			w.Write([]byte(`[{"name":"v1","commit":{"sha":"ddd"}},{"name":"v2","commit":{"sha":"eee"}}]`))
+		case "/repos/up/lib/branches":
+			if r.URL.Query().Get("page") != "1" {
+				w.Write([]byte(`[]`))
+				return
+			}
+			w.Write([]byte(`[{"name":"trunk","commit":{"sha":"ttt"}},{"name":"release","commit":{"sha":"rrr"}}]`))
+		case "/repos/up/lib/tags":
+			if r.URL.Query().Get("page") != "1" {
+				w.Write([]byte(`[]`))
+				return
+			}
+			w.Write([]byte(`[{"name":"v2","commit":{"sha":"eee"}}]`))
+		case "/repos/up/lib/compare/trunk...aaa":
+			w.Write([]byte(`{"ahead_by":0}`))
+		case "/repos/up/lib/compare/trunk...bbb", "/repos/up/lib/compare/release...bbb":
+			w.Write([]byte(`{"ahead_by":3}`))
+		case "/repos/up/lib/compare/trunk...ccc", "/repos/up/lib/compare/trunk...ddd", "/repos/up/lib/compare/release...ddd":
+			w.Write([]byte(`{"ahead_by":2}`))
+		case "/repos/up/lib/compare/release...ccc":
+			// next was merged into another upstream branch.
+			w.Write([]byte(`{"ahead_by":0}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, "token")
+	u := client.ScanUniqueness(context.Background(), Repo{FullName: "me/lib"})
+	if u.State != UniqueFound || len(u.Refs) != 2 || u.Refs[0] != "wip" || u.Refs[1] != "tags/v1" {
+		t.Fatalf("unexpected uniqueness: %#v", u)
+	}
+	if u.String() != "unique commits on 1 branch and 1 tag" {
+		t.Fatalf("unexpected label %q", u.String())
+	}
+	if u := client.ScanUniqueness(context.Background(), Repo{FullName: "me/missing"}); u.State != UniqueUnknown {
+		t.Fatalf("expected unknown for missing repo, got %#v", u)
+	}
+}
+
+func TestScanUniquenessCapsBranchCompares(t *testing.T) {
+	compares := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/repos/up/lib/compare/") {
+			compares++
+			if r.URL.Path == "/repos/up/lib/compare/feature...fff" {
+				w.Write([]byte(`{"ahead_by":0}`))
+				return
+			}
+			w.Write([]byte(`{"ahead_by":1}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/repos/me/lib":
+			w.Write([]byte(`{"full_name":"me/lib","parent":{"full_name":"up/lib"}}`))
+		case "/repos/up/lib":
+			w.Write([]byte(`{"full_name":"up/lib","default_branch":"main"}`))
+		case "/repos/me/lib/branches":
+			if r.URL.Query().Get("page") != "1" {
+				w.Write([]byte(`[]`))
+				return
+			}
+			w.Write([]byte(`[{"name":"feature","commit":{"sha":"fff"}},{"name":"wip","commit":{"sha":"www"}}]`))
+		case "/repos/up/lib/branches":
+			if r.URL.Query().Get("page") != "1" {
+				w.Write([]byte(`[]`))
+				return
+			}
+			branches := []string{`{"name":"main","commit":{"sha":"mmm"}}`}
+			for i := 0; i < 50; i++ {
+				branches = append(branches, fmt.Sprintf(`{"name":"b%02d","commit":{"sha":"s%02d"}}`, i, i))
+			}
+			// feature's commits landed upstream on the branch of the same name.
+			branches = append(branches, `{"name":"feature","commit":{"sha":"ggg"}}`)
+			w.Write([]byte("[" + strings.Join(branches, ",") + "]"))
+		case "/repos/me/lib/tags", "/repos/up/lib/tags":
+			w.Write([]byte(`[]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	u := New(ts.URL, "token").ScanUniqueness(context.Background(), Repo{FullName: "me/lib"})
+	if u.State != UniqueUnknown || u.Err == nil || !strings.Contains(u.Err.Error(), "compare wip") {
+		t.Fatalf("expected unknown once compares run out on wip, got %#v", u)
+	}
+	// feature: main, then its namesake. wip: main, then the rest of the budget.
+	if want := 2 + 1 + maxBranchCompares - 1; compares != want {
+		t.Fatalf("expected %d compares, got %d", want, compares)
+	}
+}
+
+func TestUpdateRepoAndTopics(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "PATCH /repos/me/old":
+			var body map[string]any
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body) != 1 || body["name"] != "new" {
+				t.Errorf("expected only name in body, got %v (%v)", body, err)
+				http.Error(w, "unexpected request", http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"full_name":"me/new","name":"new","topics":["a"]}`))
+		case "PATCH /repos/me/clash":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"message":"Validation Failed"}`))
+		case "GET /repos/me/new/topics":
+			w.Write([]byte(`{"names":["a","b"]}`))
+		case "PUT /repos/me/new/topics":
+			var body struct{ Names []string }
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.Names) != 0 {
+				t.Errorf("expected empty names, got %v (%v)", body, err)
+				http.Error(w, "unexpected request", http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"names":[]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, "token")
+	ctx := context.Background()
+	name := "new"
+	repo, err := client.UpdateRepo(ctx, "me/old", RepoUpdate{Name: &name})
+	if err != nil || repo.FullName != "me/new" || len(repo.Topics) != 1 {
+		t.Fatalf("unexpected update: %#v %v", repo, err)
+	}
+	if _, err := client.UpdateRepo(ctx, "me/clash", RepoUpdate{Name: &name}); !errors.Is(err, ErrValidation) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	if topics, err := client.GetTopics(ctx, "me/new"); err != nil || len(topics) != 2 {
+		t.Fatalf("unexpected topics: %v %v", topics, err)
+	}
+	if err := client.ReplaceTopics(ctx, "me/new", nil); err != nil {
+		t.Fatalf("replace topics: %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package gh
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// ErrUnsupported is returned when a provider lacks an optional capability.
+var ErrUnsupported = errors.New("not supported by this provider")
+
+// Unwrapper is implemented by providers that decorate another provider.
+type Unwrapper interface {
+	Unwrap() Provider
+}
+
+// Lookup returns the optional capability T from p or from the providers it
+// wraps. Use it for read-only capabilities; mutating ones must be
+// implemented by decorators themselves so they cannot be bypassed.
+func Lookup[T any](p Provider) (T, bool) {
+	for p != nil {
+		if c, ok := p.(T); ok {
+			return c, true
+		}
+		u, ok := p.(Unwrapper)
+		if !ok {
+			break
+		}
+		p = u.Unwrap()
+	}
+	var zero T
+	return zero, false
+}
+
+// DryRunProvider forwards reads to the wrapped provider and turns every
+// mutating call into a no-op that succeeds, so callers can rehearse the
+// full confirm, queue and logging path without changing anything.
+type DryRunProvider struct {
+	Provider
+}
+
+// DryRun wraps p in a DryRunProvider.
+func DryRun(p Provider) DryRunProvider {
+	return DryRunProvider{Provider: p}
+}
+
+// Unwrap returns the wrapped provider.
+func (d DryRunProvider) Unwrap() Provider {
+	return d.Provider
+}
+
+// DeleteRepo pretends to delete the repository.
+func (d DryRunProvider) DeleteRepo(ctx context.Context, fullName string) error {
+	return ctx.Err()
+}
+
+// ArchiveRepo pretends to archive the repository.
+func (d DryRunProvider) ArchiveRepo(ctx context.Context, fullName string) error {
+	return ctx.Err()
+}
+
+// UpdateRepo pretends to apply update and returns the repo as it would look.
+func (d DryRunProvider) UpdateRepo(ctx context.Context, fullName string, update RepoUpdate) (Repo, error) {
+	if _, ok := Lookup[RepoEditor](d.Provider); !ok {
+		return Repo{}, ErrUnsupported
+	}
+	repo, err := d.Provider.GetRepo(ctx, fullName)
+	if err != nil {
+		return Repo{}, err
+	}
+	if update.Name != nil {
+		repo.Name = *update.Name
+		repo.FullName = fullName[:strings.Index(fullName, "/")+1] + *update.Name
+	}
+	if update.Private != nil {
+		repo.Private = *update.Private
+	}
+	return repo, nil
+}
+
+// SetVisibility pretends to change the visibility. Public forks are
+// refused as the server would: their parent is public too.
+func (d DryRunProvider) SetVisibility(ctx context.Context, fullName string, private bool) error {
+	if _, ok := Lookup[VisibilitySetter](d.Provider); !ok {
+		return ErrUnsupported
+	}
+	repo, err := d.Provider.GetRepo(ctx, fullName)
+	if err != nil {
+		return err
+	}
+	if private && repo.Fork && !repo.Private {
+		return ErrForkVisibility
+	}
+	return ctx.Err()
+}
+
+// GetTopics reads the topics from the wrapped provider.
+func (d DryRunProvider) GetTopics(ctx context.Context, fullName string) ([]string, error) {
+	editor, ok := Lookup[RepoEditor](d.Provider)
+	if !ok {
+		return nil, ErrUnsupported
+	}
+	return editor.GetTopics(ctx, fullName)
+}
+
+// ReplaceTopics pretends to replace the topics.
+func (d DryRunProvider) ReplaceTopics(ctx context.Context, fullName string, topics []string) error {
+	if _, ok := Lookup[RepoEditor](d.Provider); !ok {
+		return ErrUnsupported
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,46 @@
+package gh
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDryRunSkipsMutationsButKeepsReads(t *testing.T) {
+	var mutations int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			mutations++
+		}
+		w.Write([]byte(`{"login":"octocat"}`))
+	}))
+	defer ts.Close()
+
+	p := DryRun(New(ts.URL, "token"))
+	ctx := context.Background()
+	if err := p.DeleteRepo(ctx, "me/x"); err != nil {
+		t.Fatalf("dry delete: %v", err)
+	}
+	if err := p.ArchiveRepo(ctx, "me/x"); err != nil {
+		t.Fatalf("dry archive: %v", err)
+	}
+	if err := p.ReplaceTopics(ctx, "me/x", []string{"quarantined"}); err != nil {
+		t.Fatalf("dry topics: %v", err)
+	}
+	if _, err := p.UpdateRepo(ctx, "me/x", RepoUpdate{}); err != nil {
+		t.Fatalf("dry update: %v", err)
+	}
+	if err := p.SetVisibility(ctx, "me/x", false); err != nil {
+		t.Fatalf("dry visibility: %v", err)
+	}
+	if mutations != 0 {
+		t.Fatalf("expected no mutating requests, got %d", mutations)
+	}
+	if login, err := p.CurrentUser(ctx); err != nil || login != "octocat" {
+		t.Fatalf("expected reads to pass through, got %q %v", login, err)
+	}
+	if _, ok := Lookup[UniquenessScanner](p); !ok {
+		t.Fatalf("expected read-only capability through the wrapper")
+	}
+}
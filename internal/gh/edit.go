@@ -0,0 +1,132 @@
+package gh
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrValidation wraps 422 responses: the request was understood but the
+// server refuses the change (e.g. a name clash).
+var ErrValidation = errors.New("validation failed")
+
+// ErrForkVisibility is returned by SetVisibility when the server refuses
+// to make a fork of a public repository private. Retrying cannot help.
+var ErrForkVisibility = errors.New("forks of public repositories cannot be made private")
+
+// RepoUpdate lists repository settings to change; nil fields are left alone.
+type RepoUpdate struct {
+	Name       *string `json:"name,omitempty"`
+	Private    *bool   `json:"private,omitempty"`
+	Visibility *string `json:"visibility,omitempty"`
+}
+
+// RepoEditor is implemented by providers that can edit repository settings
+// and topics.
+type RepoEditor interface {
+	UpdateRepo(ctx context.Context, fullName string, update RepoUpdate) (Repo, error)
+	GetTopics(ctx context.Context, fullName string) ([]string, error)
+	ReplaceTopics(ctx context.Context, fullName string, topics []string) error
+}
+
+var _ RepoEditor = Client{}
+
+// VisibilitySetter is implemented by providers that can switch a
+// repository between public and private.
+type VisibilitySetter interface {
+	SetVisibility(ctx context.Context, fullName string, private bool) error
+}
+
+var _ VisibilitySetter = Client{}
+
+// UpdateRepo patches repository settings and returns the updated repo.
+// Renames change the returned FullName.
+func (c Client) UpdateRepo(ctx context.Context, fullName string, update RepoUpdate) (Repo, error) {
+	if c.Token == "" {
+		return Repo{}, errors.New("GITHUB_TOKEN not set")
+	}
+	status, body, err := c.send(ctx, http.MethodPatch, "/repos/"+fullName, update)
+	if err != nil {
+		return Repo{}, err
+	}
+	if err := statusError("update "+fullName, status, body, http.StatusOK); err != nil {
+		return Repo{}, err
+	}
+	var payload apiRepo
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return Repo{}, err
+	}
+	return mapRepo(payload), nil
+}
+
+// SetVisibility makes the repository private or public. It sends both the
+// private flag and the newer visibility field, which GitHub Enterprise
+// needs for internal repos. A refusal to hide a fork wraps
+// ErrForkVisibility.
+func (c Client) SetVisibility(ctx context.Context, fullName string, private bool) error {
+	visibility := "public"
+	if private {
+		visibility = "private"
+	}
+	_, err := c.UpdateRepo(ctx, fullName, RepoUpdate{Private: &private, Visibility: &visibility})
+	if private && errors.Is(err, ErrValidation) && strings.Contains(strings.ToLower(err.Error()), "fork") {
+		return fmt.Errorf("%w: %s", ErrForkVisibility, fullName)
+	}
+	return err
+}
+
+// GetTopics returns the repository's topics.
+func (c Client) GetTopics(ctx context.Context, fullName string) ([]string, error) {
+	if c.Token == "" {
+		return nil, errors.New("GITHUB_TOKEN not set")
+	}
+	status, body, err := c.send(ctx, http.MethodGet, "/repos/"+fullName+"/topics", nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := statusError("get topics of "+fullName, status, body, http.StatusOK); err != nil {
+		return nil, err
+	}
+	var payload struct {
+		Names []string `json:"names"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	return payload.Names, nil
+}
+
+// ReplaceTopics sets the repository's topics to exactly topics.
+func (c Client) ReplaceTopics(ctx context.Context, fullName string, topics []string) error {
+	if c.Token == "" {
+		return errors.New("GITHUB_TOKEN not set")
+	}
+	if topics == nil {
+		topics = []string{}
+	}
+	status, body, err := c.send(ctx, http.MethodPut, "/repos/"+fullName+"/topics", map[string][]string{"names": topics})
+	if err != nil {
+		return err
+	}
+	return statusError("replace topics of "+fullName, status, body, http.StatusOK)
+}
+
+// statusError maps an unexpected status to an error in the same shape as
+// the older client methods, wrapping ErrValidation for 422s.
+func statusError(op string, status int, body []byte, want int) error {
+	msg := strings.TrimSpace(string(body))
+	switch status {
+	case want:
+		return nil
+	case http.StatusNotFound:
+		return fmt.Errorf("%s: %w", op, ErrNotFound)
+	case http.StatusForbidden:
+		return fmt.Errorf("%s: forbidden: %s", op, msg)
+	case http.StatusUnprocessableEntity:
+		return fmt.Errorf("%s: %w: %s", op, ErrValidation, msg)
+	}
+	return fmt.Errorf("%s: %d %s: %s", op, status, http.StatusText(status), msg)
+}
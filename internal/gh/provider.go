@@ -0,0 +1,29 @@
+package gh
+
+import (
+	"context"
+	"errors"
+)
+
+// Provider is the set of repository operations the TUI relies on. Client
+// implements it for GitHub; other hosting backends live in sibling packages
+// and map their payloads onto Repo.
+type Provider interface {
+	FetchRepos(ctx context.Context, wantForks bool) ([]Repo, error)
+	GetRepo(ctx context.Context, fullName string) (Repo, error)
+	DeleteRepo(ctx context.Context, fullName string) error
+	ArchiveRepo(ctx context.Context, fullName string) error
+	CurrentUser(ctx context.Context) (string, error)
+}
+
+var _ Provider = Client{}
+
+// ErrNotFound is wrapped by provider errors for repositories that do not
+// exist or are not visible to the token.
+var ErrNotFound = errors.New("not found")
+
+// ErrDeleteScheduled is returned by DeleteRepo when the backend accepted the
+// request but only marked the repository for delayed deletion (GitLab). The
+// repository is gone from the user's point of view but can still be
+// restored until the server purges it.
+var ErrDeleteScheduled = errors.New("scheduled for deletion")
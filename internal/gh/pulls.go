@@ -0,0 +1,132 @@
+package gh
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// PullRequest is an open pull request whose head branch lives in one of
+// the user's forks. Deleting the fork closes it upstream.
+type PullRequest struct {
+	Number  int
+	Base    string
+	Head    string
+	Title   string
+	HTMLURL string
+}
+
+// PullRequestFinder is implemented by providers that can report open pull
+// requests backed by the user's forks.
+type PullRequestFinder interface {
+	OpenPullRequestsByFork(ctx context.Context, login string) (map[string][]PullRequest, error)
+}
+
+var _ PullRequestFinder = Client{}
+
+// searchPageSize is the largest page the search API serves; it also caps
+// results at 1000 items in total.
+const searchPageSize = 100
+
+// OpenPullRequestsByFork finds open pull requests authored by login and
+// groups them by the full name of their head repository. The search API
+// does not expose the head repo, so each hit is resolved through the pulls
+// endpoint of its base repository.
+func (c Client) OpenPullRequestsByFork(ctx context.Context, login string) (map[string][]PullRequest, error) {
+	if c.Token == "" {
+		return nil, errors.New("GITHUB_TOKEN not set")
+	}
+	if login == "" {
+		return nil, errors.New("login required")
+	}
+
+	q := url.QueryEscape(fmt.Sprintf("is:pr is:open author:%s", login))
+	out := make(map[string][]PullRequest)
+	for page := 1; ; page++ {
+		status, body, err := c.send(ctx, http.MethodGet, fmt.Sprintf("/search/issues?q=%s&per_page=%d&page=%d", q, searchPageSize, page), nil)
+		if err != nil {
+			return nil, err
+		}
+		if status != http.StatusOK {
+			return nil, fmt.Errorf("search pull requests: %d: %s", status, strings.TrimSpace(string(body)))
+		}
+		var payload struct {
+			TotalCount int `json:"total_count"`
+			Items      []struct {
+				Number        int    `json:"number"`
+				RepositoryURL string `json:"repository_url"`
+			} `json:"items"`
+		}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, err
+		}
+
+		for _, item := range payload.Items {
+			base := repoFromAPIURL(item.RepositoryURL)
+			if base == "" {
+				continue
+			}
+			pr, err := c.pullRequest(ctx, base, item.Number)
+			if err != nil {
+				return nil, err
+			}
+			if pr.Head == "" {
+				// Head repo already deleted; nothing left to protect.
+				continue
+			}
+			out[pr.Head] = append(out[pr.Head], pr)
+		}
+
+		if len(payload.Items) < searchPageSize || page*searchPageSize >= payload.TotalCount {
+			break
+		}
+	}
+	return out, nil
+}
+
+func (c Client) pullRequest(ctx context.Context, base string, number int) (PullRequest, error) {
+	status, body, err := c.send(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/pulls/%d", base, number), nil)
+	if err != nil {
+		return PullRequest{}, err
+	}
+	if status != http.StatusOK {
+		return PullRequest{}, fmt.Errorf("get pull %s#%d: %d: %s", base, number, status, strings.TrimSpace(string(body)))
+	}
+	var payload struct {
+		Number  int    `json:"number"`
+		Title   string `json:"title"`
+		HTMLURL string `json:"html_url"`
+		Head    struct {
+			Repo *struct {
+				FullName string `json:"full_name"`
+			} `json:"repo"`
+		} `json:"head"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return PullRequest{}, err
+	}
+	pr := PullRequest{
+		Number:  payload.Number,
+		Base:    base,
+		Title:   payload.Title,
+		HTMLURL: payload.HTMLURL,
+	}
+	if payload.Head.Repo != nil {
+		pr.Head = payload.Head.Repo.FullName
+	}
+	return pr, nil
+}
+
+// repoFromAPIURL extracts owner/name from an API URL such as
+// https://api.github.com/repos/owner/name.
+func repoFromAPIURL(u string) string {
+	idx := strings.Index(u, "/repos/")
+	if idx < 0 {
+		return ""
+	}
+	return strings.TrimSuffix(u[idx+len("/repos/"):], "/")
+}
@@ -0,0 +1,80 @@
+package gh
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// QuarantineTopic marks quarantined repos. A second topic,
+// quarantined-YYYY-MM-DD, records the date so it travels with the repo.
+const QuarantineTopic = "quarantined"
+
+const quarantineDateLayout = "2006-01-02"
+
+// QuarantinedAt returns the quarantine date recorded in repo's topics.
+func QuarantinedAt(repo Repo) (time.Time, bool) {
+	for _, t := range repo.Topics {
+		if !strings.HasPrefix(t, QuarantineTopic+"-") {
+			continue
+		}
+		at, err := time.Parse(quarantineDateLayout, strings.TrimPrefix(t, QuarantineTopic+"-"))
+		if err == nil {
+			return at, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Quarantine retires repo in place before final deletion: it adds the
+// quarantine topics, makes the repo private where allowed and renames it
+// with prefix. Forks are left public since GitHub refuses to hide forks
+// of public repos; a 422 on the visibility change is tolerated for the same
+// reason. It returns the repo as it looks afterwards.
+func Quarantine(ctx context.Context, editor RepoEditor, repo Repo, prefix string, now time.Time) (Repo, error) {
+	topics, err := editor.GetTopics(ctx, repo.FullName)
+	if err != nil {
+		return repo, err
+	}
+	topics = addTopic(topics, QuarantineTopic)
+	topics = addTopic(topics, QuarantineTopic+"-"+now.Format(quarantineDateLayout))
+	if err := editor.ReplaceTopics(ctx, repo.FullName, topics); err != nil {
+		return repo, err
+	}
+	repo.Topics = topics
+
+	if !repo.Private && !repo.Fork {
+		private := true
+		if _, err := editor.UpdateRepo(ctx, repo.FullName, RepoUpdate{Private: &private}); err == nil {
+			repo.Private = true
+		} else if !errors.Is(err, ErrValidation) {
+			return repo, err
+		}
+	}
+
+	if prefix == "" || strings.HasPrefix(repo.Name, prefix) {
+		return repo, nil
+	}
+	name := prefix + repo.Name
+	updated, err := editor.UpdateRepo(ctx, repo.FullName, RepoUpdate{Name: &name})
+	if err != nil {
+		return repo, err
+	}
+	if updated.FullName == "" {
+		updated = repo
+		updated.Name = name
+		updated.FullName = repo.Owner + "/" + name
+	}
+	updated.Topics = topics
+	return updated, nil
+}
+
+func addTopic(topics []string, topic string) []string {
+	for _, t := range topics {
+		if t == topic {
+			return topics
+		}
+	}
+	return append(topics, topic)
+}
@@ -0,0 +1,46 @@
+package gh
+
+import (
+	"context"
+	"regexp"
+)
+
+var topicPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,49}$`)
+
+// ValidTopic reports whether GitHub accepts topic: up to 50 lowercase
+// letters, digits and hyphens, starting with a letter or digit.
+func ValidTopic(topic string) bool {
+	return topicPattern.MatchString(topic)
+}
+
+// EditTopics adds and removes topics on the repository and returns its
+// topics afterwards. Nothing is written when they already are as asked.
+func EditTopics(ctx context.Context, editor RepoEditor, fullName string, add, remove []string) (topics []string, changed bool, err error) {
+	current, err := editor.GetTopics(ctx, fullName)
+	if err != nil {
+		return nil, false, err
+	}
+	drop := make(map[string]bool, len(remove))
+	for _, t := range remove {
+		drop[t] = true
+	}
+	for _, t := range current {
+		if drop[t] {
+			changed = true
+			continue
+		}
+		topics = append(topics, t)
+	}
+	for _, t := range add {
+		before := len(topics)
+		topics = addTopic(topics, t)
+		changed = changed || len(topics) != before
+	}
+	if !changed {
+		return current, false, nil
+	}
+	if err := editor.ReplaceTopics(ctx, fullName, topics); err != nil {
+		return current, false, err
+	}
+	return topics, true, nil
+}
@@ -0,0 +1,60 @@
+package gh
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// topicEditor keeps topics in memory and counts writes.
+type topicEditor struct {
+	topics []string
+	writes int
+}
+
+func (e *topicEditor) UpdateRepo(ctx context.Context, fullName string, update RepoUpdate) (Repo, error) {
+	return Repo{}, ErrUnsupported
+}
+
+func (e *topicEditor) GetTopics(ctx context.Context, fullName string) ([]string, error) {
+	return append([]string(nil), e.topics...), nil
+}
+
+func (e *topicEditor) ReplaceTopics(ctx context.Context, fullName string, topics []string) error {
+	e.topics = append([]string(nil), topics...)
+	e.writes++
+	return nil
+}
+
+func TestEditTopics(t *testing.T) {
+	editor := &topicEditor{topics: []string{"cli", "to-review"}}
+	ctx := context.Background()
+
+	topics, changed, err := EditTopics(ctx, editor, "me/x", []string{"keep"}, []string{"to-review"})
+	if err != nil || !changed || !reflect.DeepEqual(topics, []string{"cli", "keep"}) {
+		t.Fatalf("got %v changed=%v err=%v", topics, changed, err)
+	}
+	topics, changed, err = EditTopics(ctx, editor, "me/x", []string{"keep"}, []string{"absent"})
+	if err != nil || changed || !reflect.DeepEqual(topics, []string{"cli", "keep"}) {
+		t.Fatalf("expected no change, got %v changed=%v err=%v", topics, changed, err)
+	}
+	if editor.writes != 1 {
+		t.Fatalf("expected one write, got %d", editor.writes)
+	}
+}
+
+func TestValidTopic(t *testing.T) {
+	for topic, want := range map[string]bool{
+		"to-review": true,
+		"keep":      true,
+		"go1":       true,
+		"-draft":    false,
+		"To-Review": false,
+		"two words": false,
+		"":          false,
+	} {
+		if got := ValidTopic(topic); got != want {
+			t.Errorf("ValidTopic(%q) = %v, want %v", topic, got, want)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package gh
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// UniqueState classifies whether a fork holds commits its parent lacks.
+type UniqueState int
+
+const (
+	UniqueUnknown UniqueState = iota
+	UniqueNone
+	UniqueFound
+)
+
+// Uniqueness is the outcome of scanning a fork's branches and tags against
+// its parent. Refs lists the refs whose head is not in the parent; tags are
+// prefixed with "tags/".
+type Uniqueness struct {
+	State UniqueState
+	Refs  []string
+	Err   error
+}
+
+func (u Uniqueness) String() string {
+	switch u.State {
+	case UniqueNone:
+		return "safe: nothing unique"
+	case UniqueFound:
+		var branches, tags int
+		for _, ref := range u.Refs {
+			if strings.HasPrefix(ref, "tags/") {
+				tags++
+			} else {
+				branches++
+			}
+		}
+		var parts []string
+		if branches > 0 {
+			parts = append(parts, plural(branches, "branch", "branches"))
+		}
+		if tags > 0 {
+			parts = append(parts, plural(tags, "tag", "tags"))
+		}
+		return "unique commits on " + strings.Join(parts, " and ")
+	}
+	return "unknown"
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return "1 " + one
+	}
+	return fmt.Sprintf("%d %s", n, many)
+}
+
+// UniquenessScanner is implemented by providers that can tell whether a
+// fork holds work that exists nowhere in its parent.
+type UniquenessScanner interface {
+	ScanUniqueness(ctx context.Context, repo Repo) Uniqueness
+}
+
+var _ UniquenessScanner = Client{}
+
+// maxBranchCompares caps how many compares against parent branches other
+// than the default one a scan makes, so forks of repos with hundreds of
+// branches neither time out nor spend the rate limit.
+const maxBranchCompares = 20
+
+type apiRef struct {
+	Name   string `json:"name"`
+	Commit struct {
+		SHA string `json:"sha"`
+	} `json:"commit"`
+}
+
+// ScanUniqueness lists every branch and tag of repo and checks each head
+// against the parent. A head that is also the head of a parent branch or
+// tag is not unique; otherwise it is compared with the parent's default
+// branch, then with the parent branch of the same name and the other
+// branches, and counts as unique when the compare API reports it ahead of
+// all of them. Those other compares are capped at maxBranchCompares per
+// scan. Any lookup failure, or running out of compares, makes the result
+// UniqueUnknown, never UniqueNone.
+func (c Client) ScanUniqueness(ctx context.Context, repo Repo) Uniqueness {
+	if c.Token == "" {
+		return Uniqueness{Err: errors.New("GITHUB_TOKEN not set")}
+	}
+	parentName := repo.Parent
+	if parentName == "" {
+		full, err := c.GetRepo(ctx, repo.FullName)
+		if err != nil {
+			return Uniqueness{Err: err}
+		}
+		parentName = full.Parent
+	}
+	if parentName == "" {
+		return Uniqueness{Err: fmt.Errorf("%s has no parent", repo.FullName)}
+	}
+	parent, err := c.GetRepo(ctx, parentName)
+	if err != nil {
+		return Uniqueness{Err: err}
+	}
+
+	branches, err := c.listRefs(ctx, repo.FullName, "branches")
+	if err != nil {
+		return Uniqueness{Err: err}
+	}
+	tags, err := c.listRefs(ctx, repo.FullName, "tags")
+	if err != nil {
+		return Uniqueness{Err: err}
+	}
+	for i := range tags {
+		tags[i].Name = "tags/" + tags[i].Name
+	}
+	parentBranches, err := c.listRefs(ctx, parent.FullName, "branches")
+	if err != nil {
+		return Uniqueness{Err: err}
+	}
+	parentTags, err := c.listRefs(ctx, parent.FullName, "tags")
+	if err != nil {
+		return Uniqueness{Err: err}
+	}
+	heads := make(map[string]bool)
+	var others []string
+	for _, ref := range parentBranches {
+		heads[ref.Commit.SHA] = true
+		if ref.Name != parent.DefaultBranch {
+			others = append(others, ref.Name)
+		}
+	}
+	for _, ref := range parentTags {
+		heads[ref.Commit.SHA] = true
+	}
+
+	out := Uniqueness{State: UniqueNone}
+	budget := maxBranchCompares
+	for _, ref := range append(branches, tags...) {
+		if heads[ref.Commit.SHA] {
+			continue
+		}
+		unique := true
+		for i, base := range append([]string{parent.DefaultBranch}, sameNameFirst(others, ref.Name)...) {
+			if i > 0 {
+				if budget == 0 {
+					return Uniqueness{Err: fmt.Errorf("compare %s: more than %d compares against %d parent branches", ref.Name, maxBranchCompares, len(others))}
+				}
+				budget--
+			}
+			ahead, err := c.aheadBy(ctx, parent.FullName, base, ref.Commit.SHA)
+			if err != nil {
+				return Uniqueness{Err: fmt.Errorf("compare %s: %w", ref.Name, err)}
+			}
+			if ahead == 0 {
+				unique = false
+				break
+			}
+		}
+		if unique {
+			out.State = UniqueFound
+			out.Refs = append(out.Refs, ref.Name)
+		}
+	}
+	return out
+}
+
+// sameNameFirst returns branches with name, the likeliest home of a fork
+// branch's commits, moved to the front.
+func sameNameFirst(branches []string, name string) []string {
+	out := make([]string, 0, len(branches))
+	for _, b := range branches {
+		if b == name {
+			out = append(out, b)
+		}
+	}
+	for _, b := range branches {
+		if b != name {
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// listRefs pages through /repos/{repo}/branches or /tags.
+func (c Client) listRefs(ctx context.Context, fullName, kind string) ([]apiRef, error) {
+	var refs []apiRef
+	for page := 1; ; page++ {
+		status, body, err := c.send(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/%s?per_page=100&page=%d", fullName, kind, page), nil)
+		if err != nil {
+			return nil, err
+		}
+		if status != http.StatusOK {
+			return nil, fmt.Errorf("list %s of %s: %d: %s", kind, fullName, status, strings.TrimSpace(string(body)))
+		}
+		var payload []apiRef
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, err
+		}
+		if len(payload) == 0 {
+			return refs, nil
+		}
+		refs = append(refs, payload...)
+	}
+}
+
+// aheadBy reports how many commits head has that base in repo lacks. The
+// parent shares the fork network's objects, so fork SHAs resolve there.
+func (c Client) aheadBy(ctx context.Context, repo, base, head string) (int, error) {
+	status, body, err := c.send(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/compare/%s...%s", repo, base, head), nil)
+	if err != nil {
+		return 0, err
+	}
+	if status != http.StatusOK {
+		return 0, fmt.Errorf("%d: %s", status, strings.TrimSpace(string(body)))
+	}
+	var payload struct {
+		AheadBy int `json:"ahead_by"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return 0, err
+	}
+	return payload.AheadBy, nil
+}
@@ -0,0 +1,121 @@
+package gh
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// Upstream states reported by ScanUpstream. A fork can be in several at
+// once, e.g. a parent that was renamed and later archived.
+const (
+	UpstreamArchived    = "archived"
+	UpstreamDeleted     = "deleted"
+	UpstreamRenamed     = "renamed"
+	UpstreamTransferred = "transferred"
+	UpstreamPrivate     = "private"
+)
+
+// UpstreamStates lists every state in display order.
+var UpstreamStates = []string{UpstreamDeleted, UpstreamArchived, UpstreamPrivate, UpstreamRenamed, UpstreamTransferred}
+
+// Upstream is what a scan found out about a fork's parent and source.
+// Parent is the parent's current name and ParentID its ID, which callers
+// record so the next scan can spot a rename or transfer. When the parent is
+// gone they are the recorded ones, if any.
+type Upstream struct {
+	Parent   string
+	ParentID int64
+	Source   string
+	States   []string
+	Err      error
+}
+
+// Has reports whether u includes state.
+func (u Upstream) Has(state string) bool {
+	for _, s := range u.States {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+func (u Upstream) String() string {
+	switch {
+	case u.Err != nil:
+		return "unknown"
+	case len(u.States) == 0:
+		return "ok"
+	}
+	return strings.Join(u.States, ", ")
+}
+
+// UpstreamScanner is implemented by providers that can resolve a fork's
+// parent and the root of its network.
+type UpstreamScanner interface {
+	ScanUpstream(ctx context.Context, repo Repo) Upstream
+}
+
+var _ UpstreamScanner = Client{}
+
+// ScanUpstream looks up repo, which GitHub answers with its parent and
+// source under their current names, the parent's state included. A fork
+// without a parent has been detached: its upstream was deleted, or made
+// private and is no longer visible to the token.
+//
+// GitHub never reports a parent's old name, so renames and transfers are
+// found against repo.Parent and repo.ParentID, the parent as recorded at
+// an earlier scan. The recorded ID under another name was renamed, or
+// transferred if the owner changed. A different ID means the recorded
+// parent was deleted and GitHub moved the fork to another repo of its
+// network.
+func (c Client) ScanUpstream(ctx context.Context, repo Repo) Upstream {
+	if c.Token == "" {
+		return Upstream{Err: errors.New("GITHUB_TOKEN not set")}
+	}
+	full, err := c.getRepo(ctx, repo.FullName)
+	if err != nil {
+		return Upstream{Err: err}
+	}
+	var out Upstream
+	if full.Source != nil {
+		out.Source = full.Source.FullName
+	}
+	parent := full.Parent
+	if parent == nil {
+		out.Parent, out.ParentID = repo.Parent, repo.ParentID
+		out.States = []string{UpstreamDeleted}
+		return out
+	}
+	out.Parent, out.ParentID = parent.FullName, parent.ID
+
+	known, sameID := repo.Parent, repo.ParentID == 0 || repo.ParentID == parent.ID
+	if !sameID {
+		out.States = append(out.States, UpstreamDeleted)
+	}
+	if parent.Archived {
+		out.States = append(out.States, UpstreamArchived)
+	}
+	if parent.Private {
+		out.States = append(out.States, UpstreamPrivate)
+	}
+	if known != "" && sameID && !strings.EqualFold(parent.FullName, known) {
+		if strings.EqualFold(parent.Owner(), ownerOf(known)) {
+			out.States = append(out.States, UpstreamRenamed)
+		} else {
+			out.States = append(out.States, UpstreamTransferred)
+		}
+	}
+	return out
+}
+
+// Owner is the owner part of the parent's full name.
+func (p apiParent) Owner() string {
+	return ownerOf(p.FullName)
+}
+
+func ownerOf(fullName string) string {
+	owner, _, _ := strings.Cut(fullName, "/")
+	return owner
+}
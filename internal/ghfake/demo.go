@@ -0,0 +1,85 @@
+package ghfake
+
+import (
+	"net/http"
+	"time"
+)
+
+// DemoLogin is the user the demo server authenticates as.
+const DemoLogin = "demo-user"
+
+// Demo returns a server seeded with a plausible set of forks and owned
+// repositories, a little latency and one repo whose deletion fails, so the
+// TUI can be explored without a token.
+func Demo() *Server {
+	s := New(DemoLogin)
+	s.SetLatency(150 * time.Millisecond)
+
+	now := time.Now()
+	days := func(n int) time.Time { return now.AddDate(0, 0, -n) }
+
+	upstreams := []Repo{
+		{Owner: "kubernetes", Name: "kubernetes", Language: "Go", PushedAt: days(0)},
+		{Owner: "kubernetes", Name: "kubectl", Language: "Go", PushedAt: days(2)},
+		{Owner: "kubernetes", Name: "website", Language: "HTML", PushedAt: days(1)},
+		{Owner: "charmbracelet", Name: "bubbletea", Language: "Go", PushedAt: days(3)},
+		{Owner: "rust-lang", Name: "rust", Language: "Rust", PushedAt: days(0)},
+		{Owner: "python", Name: "cpython", Language: "Python", PushedAt: days(0)},
+		{Owner: "old-org", Name: "legacy-lib", Language: "Java", Archived: true, PushedAt: days(2100)},
+	}
+	for _, u := range upstreams {
+		s.AddRepo(u)
+	}
+
+	forks := []Repo{
+		{Name: "kubernetes", Parent: "kubernetes/kubernetes", Language: "Go", Size: 812000, PushedAt: days(40)},
+		{Name: "kubectl", Parent: "kubernetes/kubectl", Language: "Go", Size: 24000, PushedAt: days(400),
+			Branches: map[string]string{"main": "sha-kubernetes-kubectl", "my-feature": "c0ffee"}},
+		{Name: "website", Parent: "kubernetes/website", Language: "HTML", Size: 530000, PushedAt: days(900)},
+		{Name: "bubbletea", Parent: "charmbracelet/bubbletea", Language: "Go", Size: 3100, PushedAt: days(12)},
+		{Name: "rust", Parent: "rust-lang/rust", Language: "Rust", Size: 905000, PushedAt: days(1300)},
+		{Name: "cpython", Parent: "python/cpython", Language: "Python", Size: 620000, PushedAt: days(200)},
+		{Name: "legacy-lib", Parent: "old-org/legacy-lib", Language: "Java", Size: 9000, Archived: true, PushedAt: days(2000)},
+		{Name: "kubectl-plugin", Parent: "kubernetes-sigs/kubectl-plugin", Language: "Go", Size: 700, PushedAt: days(600)},
+		// Deleted upstreams show up in the upstream scan. Moved ones need a
+		// parent recorded by an earlier scan, which the demo does not keep.
+		{Name: "left-pad", Parent: "gone-user/left-pad", Language: "JavaScript", Size: 40, PushedAt: days(1500)},
+	}
+	for _, f := range forks {
+		f.Owner = DemoLogin
+		s.AddRepo(f)
+	}
+
+	owned := []Repo{
+		{Name: "dotfiles", Language: "Shell", Size: 120, PushedAt: days(5)},
+		{Name: "homelab", Language: "Go", Private: true, Size: 2400, PushedAt: days(90)},
+		{Name: "blog", Language: "TypeScript", Size: 18000, PushedAt: days(700)},
+		{Name: "aoc-2019", Language: "Python", Size: 300, Archived: true, PushedAt: days(2500)},
+		// Quarantined long enough ago to show up in the purge view.
+		{Name: "zz-retired-old-site", Language: "HTML", Private: true, Size: 900, PushedAt: days(400),
+			Topics: []string{"quarantined", "quarantined-" + days(60).Format("2006-01-02")}},
+	}
+	for _, o := range owned {
+		o.Owner = DemoLogin
+		s.AddRepo(o)
+	}
+
+	s.AddRepo(Repo{Owner: "kubernetes-sigs", Name: "kubectl-plugin", Language: "Go", PushedAt: days(30)})
+
+	// An open pull request from a fork shows the delete guard.
+	s.AddPullRequest(PullRequest{
+		Base:   "charmbracelet/bubbletea",
+		Author: DemoLogin,
+		Head:   DemoLogin + "/bubbletea",
+		Title:  "Fix resize flicker on Windows",
+	})
+
+	// One failing delete shows how partial failures are reported.
+	s.Fail(Failure{
+		Method:  http.MethodDelete,
+		Path:    "/repos/" + DemoLogin + "/cpython",
+		Status:  http.StatusForbidden,
+		Message: "Must have admin rights to Repository.",
+	})
+	return s
+}
@@ -0,0 +1,779 @@
+// Package ghfake is an in-memory GitHub REST API server for tests and the
+// --demo mode. It implements the subset of endpoints the app uses and keeps
+// enough GitHub behaviour (pagination Link headers, rate-limit headers,
+// error payloads) to exercise the client realistically.
+package ghfake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Repo is a repository seeded into the fake server.
+type Repo struct {
+	ID            int64
+	Owner         string
+	Name          string
+	Private       bool
+	Archived      bool
+	Fork          bool
+	Size          int
+	Language      string
+	DefaultBranch string
+	Parent        string
+	PushedAt      time.Time
+	// Branches and Tags map ref names to head SHAs. A repo without
+	// Branches gets one default branch: a fork points at its parent's head,
+	// anything else at a SHA derived from its name.
+	Branches map[string]string
+	Tags     map[string]string
+	// Commits lists extra SHAs contained in the repo's history besides its
+	// ref heads; compare treats them as present.
+	Commits []string
+	Topics  []string
+}
+
+// FullName returns owner/name.
+func (r Repo) FullName() string {
+	return r.Owner + "/" + r.Name
+}
+
+// PullRequest is a pull request seeded into the fake server. Head is the
+// full name of the repository holding the head branch.
+type PullRequest struct {
+	Base   string
+	Number int
+	Title  string
+	Author string
+	Head   string
+	Closed bool
+}
+
+// Failure makes matching requests fail with Status. Count limits how many
+// requests fail; zero fails forever.
+type Failure struct {
+	Method  string
+	Path    string
+	Status  int
+	Message string
+	Count   int
+}
+
+// Server is an in-memory GitHub API. The zero value is not usable; call New.
+type Server struct {
+	mu        sync.Mutex
+	login     string
+	token     string
+	perPage   int
+	latency   time.Duration
+	limit     int
+	remaining int
+	reset     time.Time
+	nextID    int64
+	repos     map[string]*Repo
+	moved     map[string]string
+	pulls     []*PullRequest
+	failures  []*Failure
+	requests  []string
+	ts        *httptest.Server
+}
+
+// New returns a server that authenticates as login. Any bearer token is
+// accepted unless RequireToken is called.
+func New(login string) *Server {
+	return &Server{
+		login:     login,
+		perPage:   100,
+		limit:     5000,
+		remaining: 5000,
+		reset:     time.Now().Add(time.Hour),
+		nextID:    1,
+		repos:     make(map[string]*Repo),
+		moved:     make(map[string]string),
+	}
+}
+
+// Start serves the fake API on a local port and returns its base URL.
+func (s *Server) Start() string {
+	s.ts = httptest.NewServer(s)
+	return s.ts.URL
+}
+
+// Close stops a server started with Start.
+func (s *Server) Close() {
+	if s.ts != nil {
+		s.ts.Close()
+	}
+}
+
+// URL returns the base URL of a started server.
+func (s *Server) URL() string {
+	if s.ts == nil {
+		return ""
+	}
+	return s.ts.URL
+}
+
+// RequireToken rejects requests whose bearer token differs from token.
+func (s *Server) RequireToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = token
+}
+
+// SetPerPage caps the page size regardless of the per_page query value,
+// which makes pagination easy to exercise with small fixtures.
+func (s *Server) SetPerPage(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.perPage = n
+}
+
+// SetLatency delays every response by d.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// SetRateLimit sets the request budget reported in X-RateLimit headers.
+// Once it is spent, requests fail with 403 like the real API.
+func (s *Server) SetRateLimit(limit int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limit = limit
+	s.remaining = limit
+}
+
+// Fail registers an injected failure. Path is matched exactly against the
+// request path, e.g. /repos/me/app.
+func (s *Server) Fail(f Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, &f)
+}
+
+// AddRepo seeds a repository and returns it with defaults filled in.
+func (s *Server) AddRepo(r Repo) Repo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.ID == 0 {
+		r.ID = s.nextID
+	}
+	if r.ID >= s.nextID {
+		s.nextID = r.ID + 1
+	}
+	if r.DefaultBranch == "" {
+		r.DefaultBranch = "main"
+	}
+	if r.Parent != "" {
+		r.Fork = true
+	}
+	s.repos[strings.ToLower(r.FullName())] = &r
+	return r
+}
+
+// AddPullRequest seeds a pull request. Numbers are assigned per base repo
+// when left zero.
+func (s *Server) AddPullRequest(pr PullRequest) PullRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if pr.Number == 0 {
+		pr.Number = 1
+		for _, p := range s.pulls {
+			if strings.EqualFold(p.Base, pr.Base) && p.Number >= pr.Number {
+				pr.Number = p.Number + 1
+			}
+		}
+	}
+	s.pulls = append(s.pulls, &pr)
+	return pr
+}
+
+// PullRequests returns the current state of all seeded pull requests.
+func (s *Server) PullRequests() []PullRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]PullRequest, 0, len(s.pulls))
+	for _, p := range s.pulls {
+		out = append(out, *p)
+	}
+	return out
+}
+
+// Move renames or transfers a repository. Like GitHub, the server keeps
+// redirecting the old name to the new one, and its forks report the parent
+// under the new name.
+func (s *Server) Move(fullName, newFullName string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := strings.ToLower(fullName)
+	repo, ok := s.repos[key]
+	if !ok {
+		return false
+	}
+	owner, name, _ := strings.Cut(newFullName, "/")
+	delete(s.repos, key)
+	repo.Owner, repo.Name = owner, name
+	s.repos[strings.ToLower(newFullName)] = repo
+	s.moved[key] = repo.FullName()
+	for _, r := range s.repos {
+		if strings.EqualFold(r.Parent, fullName) {
+			r.Parent = repo.FullName()
+		}
+	}
+	return true
+}
+
+// Repo returns the current state of a seeded repository.
+func (s *Server) Repo(fullName string) (Repo, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.repos[strings.ToLower(fullName)]
+	if !ok {
+		return Repo{}, false
+	}
+	return *r, true
+}
+
+// Requests returns "METHOD /path" for every request served so far.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.requests...)
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	latency := s.latency
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+	s.mu.Unlock()
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && r.Header.Get("Authorization") != "Bearer "+s.token {
+		writeError(w, http.StatusUnauthorized, "Bad credentials")
+		return
+	}
+	if !s.spendRateLimit(w) {
+		writeError(w, http.StatusForbidden, "API rate limit exceeded for user.")
+		return
+	}
+	if f := s.matchFailure(r); f != nil {
+		writeError(w, f.Status, f.Message)
+		return
+	}
+
+	switch {
+	case r.URL.Path == "/user" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]string{"login": s.login})
+	case r.URL.Path == "/user/repos" && r.Method == http.MethodGet:
+		s.listRepos(w, r)
+	case r.URL.Path == "/search/issues" && r.Method == http.MethodGet:
+		s.searchIssues(w, r)
+	case strings.HasPrefix(r.URL.Path, "/repos/"):
+		s.serveRepo(w, r)
+	default:
+		writeError(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+func (s *Server) spendRateLimit(w http.ResponseWriter) bool {
+	if time.Now().After(s.reset) {
+		s.remaining = s.limit
+		s.reset = time.Now().Add(time.Hour)
+	}
+	ok := s.remaining > 0
+	if ok {
+		s.remaining--
+	}
+	h := w.Header()
+	h.Set("X-RateLimit-Limit", strconv.Itoa(s.limit))
+	h.Set("X-RateLimit-Remaining", strconv.Itoa(s.remaining))
+	h.Set("X-RateLimit-Used", strconv.Itoa(s.limit-s.remaining))
+	h.Set("X-RateLimit-Reset", strconv.FormatInt(s.reset.Unix(), 10))
+	return ok
+}
+
+func (s *Server) matchFailure(r *http.Request) *Failure {
+	for i, f := range s.failures {
+		if f.Method != "" && f.Method != r.Method {
+			continue
+		}
+		if f.Path != r.URL.Path {
+			continue
+		}
+		if f.Count > 0 {
+			f.Count--
+			if f.Count == 0 {
+				s.failures = append(s.failures[:i], s.failures[i+1:]...)
+			}
+		}
+		out := *f
+		if out.Message == "" {
+			out.Message = http.StatusText(out.Status)
+		}
+		return &out
+	}
+	return nil
+}
+
+func (s *Server) listRepos(w http.ResponseWriter, r *http.Request) {
+	var owned []*Repo
+	for _, repo := range s.repos {
+		if strings.EqualFold(repo.Owner, s.login) {
+			owned = append(owned, repo)
+		}
+	}
+	sort.Slice(owned, func(i, j int) bool { return owned[i].ID < owned[j].ID })
+
+	perPage := s.perPage
+	if n, err := strconv.Atoi(r.URL.Query().Get("per_page")); err == nil && n > 0 && n < perPage {
+		perPage = n
+	}
+	page := 1
+	if n, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && n > 0 {
+		page = n
+	}
+	last := (len(owned) + perPage - 1) / perPage
+	if last == 0 {
+		last = 1
+	}
+	if link := linkHeader(r, page, last); link != "" {
+		w.Header().Set("Link", link)
+	}
+
+	start := (page - 1) * perPage
+	out := []apiRepo{}
+	for i := start; i < start+perPage && i < len(owned); i++ {
+		// Like the real list endpoint, only single-repo lookups carry the
+		// parent and source.
+		repo := s.toAPI(owned[i])
+		repo.Parent, repo.Source = nil, nil
+		out = append(out, repo)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) serveRepo(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/repos/"), "/")
+	if len(parts) < 2 {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	key := strings.ToLower(parts[0] + "/" + parts[1])
+	repo, ok := s.repos[key]
+	if !ok {
+		if to, moved := s.moved[key]; moved && r.Method == http.MethodGet {
+			target := "/repos/" + strings.Join(append([]string{to}, parts[2:]...), "/")
+			if r.URL.RawQuery != "" {
+				target += "?" + r.URL.RawQuery
+			}
+			http.Redirect(w, r, target, http.StatusMovedPermanently)
+			return
+		}
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	if len(parts) > 2 {
+		s.serveRepoSub(w, r, repo, parts[2:])
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.toAPI(repo))
+	case http.MethodDelete:
+		if !strings.EqualFold(repo.Owner, s.login) {
+			writeError(w, http.StatusForbidden, "Must have admin rights to Repository.")
+			return
+		}
+		delete(s.repos, key)
+		// Deleting the head repo closes its pull requests upstream.
+		for _, p := range s.pulls {
+			if strings.EqualFold(p.Head, repo.FullName()) {
+				p.Closed = true
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodPatch:
+		if !strings.EqualFold(repo.Owner, s.login) {
+			writeError(w, http.StatusForbidden, "Must have admin rights to Repository.")
+			return
+		}
+		var patch struct {
+			Archived   *bool   `json:"archived"`
+			Private    *bool   `json:"private"`
+			Visibility *string `json:"visibility"`
+			Name       *string `json:"name"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+			writeError(w, http.StatusBadRequest, "Problems parsing JSON")
+			return
+		}
+		if patch.Visibility != nil {
+			private := *patch.Visibility != "public"
+			patch.Private = &private
+		}
+		if patch.Private != nil && *patch.Private && repo.Fork && !repo.Private {
+			// GitHub refuses to hide a fork of a public repository.
+			writeError(w, http.StatusUnprocessableEntity, "Validation Failed: forks of public repositories cannot be made private")
+			return
+		}
+		if patch.Name != nil && !strings.EqualFold(*patch.Name, repo.Name) {
+			renamed := strings.ToLower(repo.Owner + "/" + *patch.Name)
+			if _, taken := s.repos[renamed]; taken {
+				writeError(w, http.StatusUnprocessableEntity, "Validation Failed: name already exists on this account")
+				return
+			}
+			delete(s.repos, key)
+			s.repos[renamed] = repo
+			s.moved[key] = repo.Owner + "/" + *patch.Name
+		}
+		if patch.Name != nil {
+			repo.Name = *patch.Name
+		}
+		if patch.Archived != nil {
+			repo.Archived = *patch.Archived
+		}
+		if patch.Private != nil {
+			repo.Private = *patch.Private
+		}
+		writeJSON(w, http.StatusOK, s.toAPI(repo))
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+// serveRepoSub handles /repos/{owner}/{name}/... sub-resources.
+func (s *Server) serveRepoSub(w http.ResponseWriter, r *http.Request, repo *Repo, rest []string) {
+	switch {
+	case len(rest) == 1 && (rest[0] == "branches" || rest[0] == "tags") && r.Method == http.MethodGet:
+		refs := repo.Tags
+		if rest[0] == "branches" {
+			refs = s.branches(repo)
+		}
+		names := make([]string, 0, len(refs))
+		for name := range refs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		out := []map[string]any{}
+		if page, _ := strconv.Atoi(r.URL.Query().Get("page")); page <= 1 {
+			for _, name := range names {
+				out = append(out, map[string]any{"name": name, "commit": map[string]string{"sha": refs[name]}})
+			}
+		}
+		writeJSON(w, http.StatusOK, out)
+		return
+	case len(rest) == 2 && rest[0] == "compare" && r.Method == http.MethodGet:
+		base, head, ok := strings.Cut(rest[1], "...")
+		if !ok {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		ahead := 1
+		if s.contains(repo, head) {
+			ahead = 0
+		}
+		status := "ahead"
+		if ahead == 0 {
+			status = "behind"
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"status": status, "ahead_by": ahead, "base_commit": map[string]string{"sha": base}})
+		return
+	case len(rest) == 1 && rest[0] == "topics" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string][]string{"names": topicNames(repo.Topics)})
+		return
+	case len(rest) == 1 && rest[0] == "topics" && r.Method == http.MethodPut:
+		if !strings.EqualFold(repo.Owner, s.login) {
+			writeError(w, http.StatusForbidden, "Must have admin rights to Repository.")
+			return
+		}
+		var body struct {
+			Names []string `json:"names"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Names == nil {
+			writeError(w, http.StatusUnprocessableEntity, "Invalid request.\n\n\"names\" wasn't supplied.")
+			return
+		}
+		repo.Topics = append([]string{}, body.Names...)
+		writeJSON(w, http.StatusOK, map[string][]string{"names": topicNames(repo.Topics)})
+		return
+	case len(rest) == 2 && rest[0] == "pulls" && r.Method == http.MethodGet:
+		n, _ := strconv.Atoi(rest[1])
+		for _, p := range s.pulls {
+			if strings.EqualFold(p.Base, repo.FullName()) && p.Number == n {
+				writeJSON(w, http.StatusOK, s.pullToAPI(r, p))
+				return
+			}
+		}
+	}
+	writeError(w, http.StatusNotFound, "Not Found")
+}
+
+// branches returns repo's branches, applying the default described on Repo.
+func (s *Server) branches(repo *Repo) map[string]string {
+	if repo.Branches != nil {
+		return repo.Branches
+	}
+	return map[string]string{repo.DefaultBranch: s.headSHA(repo)}
+}
+
+func (s *Server) headSHA(repo *Repo) string {
+	if repo.Branches != nil {
+		return repo.Branches[repo.DefaultBranch]
+	}
+	if parent, ok := s.repos[strings.ToLower(repo.Parent)]; ok && parent != repo {
+		return s.headSHA(parent)
+	}
+	return "sha-" + strings.ReplaceAll(repo.FullName(), "/", "-")
+}
+
+// contains reports whether sha is reachable in repo: a branch or tag head,
+// or one of its extra Commits.
+func (s *Server) contains(repo *Repo, sha string) bool {
+	for _, h := range s.branches(repo) {
+		if h == sha {
+			return true
+		}
+	}
+	for _, h := range repo.Tags {
+		if h == sha {
+			return true
+		}
+	}
+	for _, c := range repo.Commits {
+		if c == sha {
+			return true
+		}
+	}
+	return false
+}
+
+// searchIssues understands the is:pr, is:open and author: qualifiers,
+// which is all the client sends.
+func (s *Server) searchIssues(w http.ResponseWriter, r *http.Request) {
+	var author string
+	openOnly := false
+	for _, term := range strings.Fields(r.URL.Query().Get("q")) {
+		switch {
+		case term == "is:open":
+			openOnly = true
+		case strings.HasPrefix(term, "author:"):
+			author = strings.TrimPrefix(term, "author:")
+		}
+	}
+
+	type item struct {
+		Number        int    `json:"number"`
+		Title         string `json:"title"`
+		RepositoryURL string `json:"repository_url"`
+		HTMLURL       string `json:"html_url"`
+	}
+	var matches []item
+	for _, p := range s.pulls {
+		if openOnly && p.Closed {
+			continue
+		}
+		if author != "" && !strings.EqualFold(p.Author, author) {
+			continue
+		}
+		matches = append(matches, item{
+			Number:        p.Number,
+			Title:         p.Title,
+			RepositoryURL: "http://" + r.Host + "/repos/" + p.Base,
+			HTMLURL:       fmt.Sprintf("https://github.com/%s/pull/%d", p.Base, p.Number),
+		})
+	}
+
+	perPage := 30
+	if n, err := strconv.Atoi(r.URL.Query().Get("per_page")); err == nil && n > 0 {
+		perPage = n
+	}
+	page := 1
+	if n, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && n > 0 {
+		page = n
+	}
+	items := []item{}
+	for i := (page - 1) * perPage; i < page*perPage && i < len(matches); i++ {
+		items = append(items, matches[i])
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"total_count":        len(matches),
+		"incomplete_results": false,
+		"items":              items,
+	})
+}
+
+func (s *Server) pullToAPI(r *http.Request, p *PullRequest) map[string]any {
+	state := "open"
+	if p.Closed {
+		state = "closed"
+	}
+	var headRepo any
+	if h, ok := s.repos[strings.ToLower(p.Head)]; ok {
+		headRepo = map[string]string{"full_name": h.FullName()}
+	}
+	return map[string]any{
+		"number":   p.Number,
+		"title":    p.Title,
+		"state":    state,
+		"html_url": fmt.Sprintf("https://github.com/%s/pull/%d", p.Base, p.Number),
+		"user":     apiOwner{Login: p.Author},
+		"head":     map[string]any{"repo": headRepo},
+	}
+}
+
+type apiOwner struct {
+	Login string `json:"login"`
+}
+
+type apiParent struct {
+	ID       int64  `json:"id"`
+	FullName string `json:"full_name"`
+	Private  bool   `json:"private"`
+	Archived bool   `json:"archived"`
+}
+
+// resolve finds a repo by name, following renames and transfers.
+func (s *Server) resolve(fullName string) (*Repo, bool) {
+	key := strings.ToLower(fullName)
+	for i := 0; i < 10; i++ {
+		if repo, ok := s.repos[key]; ok {
+			return repo, true
+		}
+		to, moved := s.moved[key]
+		if !moved {
+			break
+		}
+		key = strings.ToLower(to)
+	}
+	return nil, false
+}
+
+// sourceRepo walks up the fork network to its root, or returns nil when r
+// has no parent left. Past a missing ancestor, the root is the furthest
+// one still around.
+func (s *Server) sourceRepo(r *Repo) *Repo {
+	root := r
+	for i := 0; i < 10 && root.Parent != ""; i++ {
+		parent, ok := s.resolve(root.Parent)
+		if !ok {
+			break
+		}
+		root = parent
+	}
+	if root == r {
+		return nil
+	}
+	return root
+}
+
+type apiRepo struct {
+	ID            int64      `json:"id"`
+	Name          string     `json:"name"`
+	FullName      string     `json:"full_name"`
+	Private       bool       `json:"private"`
+	Archived      bool       `json:"archived"`
+	Fork          bool       `json:"fork"`
+	Size          int        `json:"size"`
+	Language      string     `json:"language,omitempty"`
+	DefaultBranch string     `json:"default_branch"`
+	PushedAt      time.Time  `json:"pushed_at"`
+	Owner         apiOwner   `json:"owner"`
+	Parent        *apiParent `json:"parent,omitempty"`
+	Source        *apiParent `json:"source,omitempty"`
+	HTMLURL       string     `json:"html_url"`
+	SSHURL        string     `json:"ssh_url"`
+	Topics        []string   `json:"topics"`
+}
+
+func (s *Server) toAPI(r *Repo) apiRepo {
+	out := apiRepo{
+		ID:            r.ID,
+		Name:          r.Name,
+		FullName:      r.FullName(),
+		Private:       r.Private,
+		Archived:      r.Archived,
+		Fork:          r.Fork,
+		Size:          r.Size,
+		Language:      r.Language,
+		DefaultBranch: r.DefaultBranch,
+		PushedAt:      r.PushedAt,
+		Owner:         apiOwner{Login: r.Owner},
+		HTMLURL:       "https://github.com/" + r.FullName(),
+		SSHURL:        fmt.Sprintf("git@github.com:%s.git", r.FullName()),
+		Topics:        topicNames(r.Topics),
+	}
+	// Like GitHub, the parent goes by its current name, and a fork whose
+	// parent is gone has none.
+	if parent, ok := s.resolve(r.Parent); ok && r.Parent != "" {
+		out.Parent = parentOf(parent)
+	}
+	if root := s.sourceRepo(r); root != nil {
+		out.Source = parentOf(root)
+	}
+	return out
+}
+
+func parentOf(r *Repo) *apiParent {
+	return &apiParent{ID: r.ID, FullName: r.FullName(), Private: r.Private, Archived: r.Archived}
+}
+
+// topicNames returns topics as GitHub reports them: never null.
+func topicNames(topics []string) []string {
+	if topics == nil {
+		return []string{}
+	}
+	return topics
+}
+
+// linkHeader builds a GitHub-style Link header for page out of last.
+func linkHeader(r *http.Request, page, last int) string {
+	ref := func(p int, rel string) string {
+		u := url.URL{Scheme: "http", Host: r.Host, Path: r.URL.Path}
+		q := r.URL.Query()
+		q.Set("page", strconv.Itoa(p))
+		u.RawQuery = q.Encode()
+		return fmt.Sprintf(`<%s>; rel="%s"`, u.String(), rel)
+	}
+	var links []string
+	if page < last {
+		links = append(links, ref(page+1, "next"), ref(last, "last"))
+	}
+	if page > 1 {
+		links = append(links, ref(1, "first"), ref(page-1, "prev"))
+	}
+	return strings.Join(links, ", ")
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{
+		"message":           message,
+		"documentation_url": "https://docs.github.com/rest",
+	})
+}
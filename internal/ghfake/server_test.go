@@ -0,0 +1,248 @@
+package ghfake
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/seeg/github-fork-manager/internal/gh"
+)
+
+func seeded(t *testing.T) (*Server, gh.Client) {
+	t.Helper()
+	s := New("me")
+	s.AddRepo(Repo{Owner: "up", Name: "lib"})
+	for _, name := range []string{"a", "b", "c"} {
+		s.AddRepo(Repo{Owner: "me", Name: name, Parent: "up/lib"})
+	}
+	s.AddRepo(Repo{Owner: "me", Name: "own"})
+	url := s.Start()
+	t.Cleanup(s.Close)
+	return s, gh.New(url, "token")
+}
+
+func TestClientPaginatesThroughFake(t *testing.T) {
+	s, client := seeded(t)
+	s.SetPerPage(2)
+
+	forks, err := client.FetchRepos(context.Background(), true)
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if len(forks) != 3 {
+		t.Fatalf("expected 3 forks across pages, got %d", len(forks))
+	}
+	if forks[0].Parent != "" {
+		t.Fatalf("expected the listing to leave the parent out, got %#v", forks[0])
+	}
+}
+
+func TestLinkAndRateLimitHeaders(t *testing.T) {
+	s, _ := seeded(t)
+	s.SetPerPage(2)
+	s.SetRateLimit(2)
+
+	resp, err := http.Get(s.URL() + "/user/repos?page=1")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	resp.Body.Close()
+	if link := resp.Header.Get("Link"); !strings.Contains(link, `page=2>; rel="next"`) {
+		t.Fatalf("expected next link, got %q", link)
+	}
+	if got := resp.Header.Get("X-RateLimit-Remaining"); got != "1" {
+		t.Fatalf("expected 1 remaining, got %q", got)
+	}
+
+	http.Get(s.URL() + "/user")
+	resp, err = http.Get(s.URL() + "/user")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected rate limit 403, got %d", resp.StatusCode)
+	}
+}
+
+func TestInjectedFailuresAndDelete(t *testing.T) {
+	s, client := seeded(t)
+	s.Fail(Failure{Method: http.MethodDelete, Path: "/repos/me/a", Status: http.StatusBadGateway, Count: 1})
+	ctx := context.Background()
+
+	if err := client.DeleteRepo(ctx, "me/a"); err == nil {
+		t.Fatalf("expected injected failure")
+	}
+	if err := client.DeleteRepo(ctx, "me/a"); err != nil {
+		t.Fatalf("expected failure to be used up, got %v", err)
+	}
+	if _, ok := s.Repo("me/a"); ok {
+		t.Fatalf("expected repo to be deleted")
+	}
+	if err := client.DeleteRepo(ctx, "up/lib"); err == nil {
+		t.Fatalf("expected forbidden deleting someone else's repo")
+	}
+	if err := client.ArchiveRepo(ctx, "me/b"); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	if r, _ := s.Repo("me/b"); !r.Archived {
+		t.Fatalf("expected archived repo")
+	}
+}
+
+func TestLatencyHonoursContext(t *testing.T) {
+	s, client := seeded(t)
+	s.SetLatency(time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.CurrentUser(ctx); err == nil {
+		t.Fatalf("expected timeout")
+	}
+}
+
+func TestPullRequestsCloseWhenHeadDeleted(t *testing.T) {
+	s, client := seeded(t)
+	s.AddPullRequest(PullRequest{Base: "up/lib", Author: "me", Head: "me/a", Title: "Fix"})
+	s.AddPullRequest(PullRequest{Base: "up/lib", Author: "someone", Head: "someone/lib"})
+	ctx := context.Background()
+
+	prs, err := client.OpenPullRequestsByFork(ctx, "me")
+	if err != nil {
+		t.Fatalf("open prs: %v", err)
+	}
+	if len(prs["me/a"]) != 1 || prs["me/a"][0].Number != 1 {
+		t.Fatalf("unexpected prs: %#v", prs)
+	}
+
+	if err := client.DeleteRepo(ctx, "me/a"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if !s.PullRequests()[0].Closed {
+		t.Fatalf("expected pull request to be closed with its head repo")
+	}
+}
+
+func TestScanUniquenessAgainstFake(t *testing.T) {
+	s, client := seeded(t)
+	s.AddRepo(Repo{Owner: "up", Name: "tool", Branches: map[string]string{"main": "p1"}, Commits: []string{"p0"}})
+	s.AddRepo(Repo{Owner: "me", Name: "tool", Parent: "up/tool", Branches: map[string]string{
+		"main":    "p1",
+		"old":     "p0",
+		"feature": "f1",
+	}, Tags: map[string]string{"v1": "f2"}})
+	ctx := context.Background()
+
+	u := client.ScanUniqueness(ctx, gh.Repo{FullName: "me/tool", Parent: "up/tool"})
+	if u.State != gh.UniqueFound || len(u.Refs) != 2 || u.Refs[0] != "feature" || u.Refs[1] != "tags/v1" {
+		t.Fatalf("unexpected uniqueness: %#v", u)
+	}
+
+	if u := client.ScanUniqueness(ctx, gh.Repo{FullName: "me/a"}); u.State != gh.UniqueNone {
+		t.Fatalf("expected default fork to be safe, got %#v", u)
+	}
+
+	s.Fail(Failure{Path: "/repos/me/b/tags", Status: http.StatusBadGateway})
+	if u := client.ScanUniqueness(ctx, gh.Repo{FullName: "me/b", Parent: "up/lib"}); u.State != gh.UniqueUnknown || u.Err == nil {
+		t.Fatalf("expected unknown on failure, got %#v", u)
+	}
+}
+
+func TestQuarantineAgainstFake(t *testing.T) {
+	s, client := seeded(t)
+	s.AddRepo(Repo{Owner: "me", Name: "zz-retired-taken"})
+	s.AddRepo(Repo{Owner: "me", Name: "taken", Topics: []string{"go"}})
+	ctx := context.Background()
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	own, err := client.GetRepo(ctx, "me/own")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	got, err := gh.Quarantine(ctx, client, own, "zz-retired-", now)
+	if err != nil {
+		t.Fatalf("quarantine: %v", err)
+	}
+	if got.FullName != "me/zz-retired-own" || !got.Private {
+		t.Fatalf("unexpected result: %#v", got)
+	}
+	if at, ok := gh.QuarantinedAt(got); !ok || !at.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected quarantine date, got %v %v", at, ok)
+	}
+	if _, ok := s.Repo("me/own"); ok {
+		t.Fatalf("expected old name to be gone")
+	}
+	if r, ok := s.Repo("me/zz-retired-own"); !ok || !r.Private || len(r.Topics) != 2 {
+		t.Fatalf("unexpected server state: %#v", r)
+	}
+
+	// Forks stay public; the rename still happens.
+	fork, _ := client.GetRepo(ctx, "me/a")
+	if got, err := gh.Quarantine(ctx, client, fork, "zz-retired-", now); err != nil || got.Private || got.Name != "zz-retired-a" {
+		t.Fatalf("unexpected fork quarantine: %#v %v", got, err)
+	}
+
+	// A name clash fails after tagging, leaving the repo in place.
+	taken, _ := client.GetRepo(ctx, "me/taken")
+	if _, err := gh.Quarantine(ctx, client, taken, "zz-retired-", now); !errors.Is(err, gh.ErrValidation) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	if r, _ := s.Repo("me/taken"); len(r.Topics) != 3 || r.Topics[0] != "go" {
+		t.Fatalf("expected existing topics to be kept, got %v", r.Topics)
+	}
+}
+
+func TestScanUpstreamAgainstFake(t *testing.T) {
+	s := New("me")
+	s.AddRepo(Repo{Owner: "root", Name: "lib"})
+	s.AddRepo(Repo{Owner: "up", Name: "lib", Parent: "root/lib"})
+	s.AddRepo(Repo{Owner: "up", Name: "old", Archived: true})
+	s.AddRepo(Repo{Owner: "up", Name: "tool"})
+	s.AddRepo(Repo{Owner: "up", Name: "app"})
+	s.AddRepo(Repo{Owner: "me", Name: "lib", Parent: "up/lib"})
+	s.AddRepo(Repo{Owner: "me", Name: "old", Parent: "up/old"})
+	s.AddRepo(Repo{Owner: "me", Name: "gone", Parent: "ghost/gone"})
+	s.AddRepo(Repo{Owner: "me", Name: "tool", Parent: "up/tool"})
+	s.AddRepo(Repo{Owner: "me", Name: "app", Parent: "up/app"})
+	url := s.Start()
+	t.Cleanup(s.Close)
+	client := gh.New(url, "token")
+	ctx := context.Background()
+
+	// A first scan records the parents; the moves only show against them.
+	recorded := make(map[string]gh.Upstream)
+	for _, fork := range []string{"me/tool", "me/app"} {
+		got := client.ScanUpstream(ctx, gh.Repo{FullName: fork, Fork: true})
+		if got.Err != nil || got.String() != "ok" || got.ParentID == 0 {
+			t.Fatalf("%s: first scan got %+v", fork, got)
+		}
+		recorded[fork] = got
+	}
+	s.Move("up/tool", "up/tool2")
+	s.Move("up/app", "neworg/app")
+	if fork, _ := s.Repo("me/tool"); fork.Parent != "up/tool2" {
+		t.Fatalf("expected the fork to follow its parent's move, got %q", fork.Parent)
+	}
+
+	cases := []struct {
+		fork, parent, source, states string
+	}{
+		{"me/lib", "up/lib", "root/lib", "ok"},
+		{"me/old", "up/old", "up/old", "archived"},
+		{"me/gone", "", "", "deleted"},
+		{"me/tool", "up/tool2", "up/tool2", "renamed"},
+		{"me/app", "neworg/app", "neworg/app", "transferred"},
+	}
+	for _, tc := range cases {
+		known := recorded[tc.fork]
+		got := client.ScanUpstream(ctx, gh.Repo{FullName: tc.fork, Fork: true, Parent: known.Parent, ParentID: known.ParentID})
+		if got.Err != nil {
+			t.Fatalf("%s: %v", tc.fork, got.Err)
+		}
+		if got.Parent != tc.parent || got.Source != tc.source || got.String() != tc.states {
+			t.Fatalf("%s: got parent %q source %q states %q", tc.fork, got.Parent, got.Source, got)
+		}
+	}
+}
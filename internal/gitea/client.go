@@ -0,0 +1,224 @@
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/seeg/github-fork-manager/internal/gh"
+)
+
+// Client is a minimal Gitea/Forgejo client. Both servers expose the same
+// REST shapes under /api/v1, so one implementation covers them.
+type Client struct {
+	BaseURL    string
+	Token      string
+	HTTPClient *http.Client
+}
+
+var _ gh.Provider = Client{}
+
+// pageSize matches the default maximum page size of Gitea servers.
+const pageSize = 50
+
+// New returns a Client with defaults applied. baseURL should include the
+// API prefix, e.g. https://codeberg.org/api/v1.
+func New(baseURL, token string) Client {
+	return Client{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		Token:   token,
+		HTTPClient: &http.Client{
+			Timeout: 15 * time.Second,
+		},
+	}
+}
+
+// FetchRepos retrieves repositories owned by the authenticated user,
+// optionally restricted to forks.
+func (c Client) FetchRepos(ctx context.Context, wantForks bool) ([]gh.Repo, error) {
+	if c.Token == "" {
+		return nil, errors.New("gitea token not set")
+	}
+	login, err := c.CurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var repos []gh.Repo
+	page := 1
+
+	for {
+		body, err := c.do(ctx, http.MethodGet, fmt.Sprintf("/user/repos?limit=%d&page=%d", pageSize, page), nil, http.StatusOK)
+		if err != nil {
+			return nil, fmt.Errorf("list repos: %w", err)
+		}
+
+		var payload []apiRepo
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, err
+		}
+
+		if len(payload) == 0 {
+			break
+		}
+
+		for _, r := range payload {
+			// /user/repos also returns repos the user can reach through
+			// organisations; only keep the ones they own.
+			if !strings.EqualFold(r.Owner.Login, login) {
+				continue
+			}
+			if wantForks != r.Fork {
+				continue
+			}
+			repos = append(repos, mapRepo(r))
+		}
+
+		page++
+	}
+
+	return repos, nil
+}
+
+// GetRepo fetches a single repository by full name.
+func (c Client) GetRepo(ctx context.Context, fullName string) (gh.Repo, error) {
+	if c.Token == "" {
+		return gh.Repo{}, errors.New("gitea token not set")
+	}
+	body, err := c.do(ctx, http.MethodGet, "/repos/"+fullName, nil, http.StatusOK)
+	if err != nil {
+		return gh.Repo{}, fmt.Errorf("get %s: %w", fullName, err)
+	}
+	var payload apiRepo
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return gh.Repo{}, err
+	}
+	return mapRepo(payload), nil
+}
+
+// DeleteRepo deletes a repository by full name.
+func (c Client) DeleteRepo(ctx context.Context, fullName string) error {
+	if c.Token == "" {
+		return errors.New("gitea token not set")
+	}
+	if _, err := c.do(ctx, http.MethodDelete, "/repos/"+fullName, nil, http.StatusNoContent); err != nil {
+		return fmt.Errorf("delete %s: %w", fullName, err)
+	}
+	return nil
+}
+
+// ArchiveRepo marks a repository as archived.
+func (c Client) ArchiveRepo(ctx context.Context, fullName string) error {
+	if c.Token == "" {
+		return errors.New("gitea token not set")
+	}
+	if _, err := c.do(ctx, http.MethodPatch, "/repos/"+fullName, strings.NewReader(`{"archived":true}`), http.StatusOK); err != nil {
+		return fmt.Errorf("archive %s: %w", fullName, err)
+	}
+	return nil
+}
+
+// CurrentUser fetches the login of the authenticated user.
+func (c Client) CurrentUser(ctx context.Context) (string, error) {
+	if c.Token == "" {
+		return "", errors.New("gitea token not set")
+	}
+	body, err := c.do(ctx, http.MethodGet, "/user", nil, http.StatusOK)
+	if err != nil {
+		return "", fmt.Errorf("whoami: %w", err)
+	}
+	var payload struct {
+		Login string `json:"login"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return "", err
+	}
+	return payload.Login, nil
+}
+
+// do sends a request and returns the body when the response status matches
+// want. Other statuses are turned into errors carrying the server message.
+func (c Client) do(ctx context.Context, method, path string, payload io.Reader, want int) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, payload)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "token "+c.Token)
+	req.Header.Set("User-Agent", "github-fork-manager")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == want:
+		return body, nil
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, gh.ErrNotFound
+	case resp.StatusCode == http.StatusForbidden:
+		return nil, fmt.Errorf("forbidden: %s", strings.TrimSpace(string(body)))
+	}
+	return nil, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+}
+
+type apiRepo struct {
+	ID            int64     `json:"id"`
+	Name          string    `json:"name"`
+	FullName      string    `json:"full_name"`
+	Private       bool      `json:"private"`
+	Archived      bool      `json:"archived"`
+	Fork          bool      `json:"fork"`
+	Size          int       `json:"size"`
+	Language      string    `json:"language"`
+	DefaultBranch string    `json:"default_branch"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	Owner         struct {
+		Login string `json:"login"`
+	} `json:"owner"`
+	Parent *struct {
+		FullName string `json:"full_name"`
+	} `json:"parent"`
+	HTMLURL string `json:"html_url"`
+	SSHURL  string `json:"ssh_url"`
+}
+
+// mapRepo converts a Gitea payload into the shared repo model. Gitea has no
+// pushed_at, so updated_at stands in for the last push.
+func mapRepo(r apiRepo) gh.Repo {
+	parent := ""
+	if r.Parent != nil {
+		parent = r.Parent.FullName
+	}
+
+	return gh.Repo{
+		ID:            r.ID,
+		Name:          r.Name,
+		FullName:      r.FullName,
+		Owner:         r.Owner.Login,
+		Private:       r.Private,
+		Archived:      r.Archived,
+		Fork:          r.Fork,
+		Size:          r.Size,
+		Language:      r.Language,
+		DefaultBranch: r.DefaultBranch,
+		Parent:        parent,
+		PushedAt:      r.UpdatedAt,
+		HTMLURL:       r.HTMLURL,
+		SSHURL:        r.SSHURL,
+	}
+}
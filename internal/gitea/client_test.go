@@ -0,0 +1,95 @@
+package gitea
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/seeg/github-fork-manager/internal/gh"
+)
+
+func TestFetchReposKeepsOwnedForks(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "token secret" {
+			t.Errorf("unexpected auth header %q", got)
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Path {
+		case "/user":
+			w.Write([]byte(`{"login":"me"}`))
+		case "/user/repos":
+			if r.URL.Query().Get("page") != "1" {
+				w.Write([]byte(`[]`))
+				return
+			}
+			w.Write([]byte(`[
+				{"full_name":"me/forked","fork":true,"owner":{"login":"me"},"parent":{"full_name":"up/forked"},"updated_at":"2024-01-02T03:04:05Z"},
+				{"full_name":"me/owned","fork":false,"owner":{"login":"me"}},
+				{"full_name":"org/forked","fork":true,"owner":{"login":"org"}}
+			]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, "secret")
+	ctx := context.Background()
+
+	forks, err := client.FetchRepos(ctx, true)
+	if err != nil {
+		t.Fatalf("fetch forks: %v", err)
+	}
+	if len(forks) != 1 || forks[0].FullName != "me/forked" {
+		t.Fatalf("expected only owned fork, got %#v", forks)
+	}
+	if forks[0].Parent != "up/forked" || forks[0].PushedAt.IsZero() {
+		t.Fatalf("expected parent and updated_at mapped, got %#v", forks[0])
+	}
+
+	owned, err := client.FetchRepos(ctx, false)
+	if err != nil {
+		t.Fatalf("fetch owned: %v", err)
+	}
+	if len(owned) != 1 || owned[0].FullName != "me/owned" {
+		t.Fatalf("expected only owned repo, got %#v", owned)
+	}
+}
+
+func TestDeleteAndArchive(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodDelete && r.URL.Path == "/repos/me/ok":
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodPatch && r.URL.Path == "/repos/me/ok":
+			w.Write([]byte(`{"full_name":"me/ok","archived":true}`))
+		case r.URL.Path == "/repos/me/forbidden":
+			http.Error(w, "nope", http.StatusForbidden)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, "secret")
+	ctx := context.Background()
+
+	if err := client.DeleteRepo(ctx, "me/ok"); err != nil {
+		t.Fatalf("delete ok: %v", err)
+	}
+	if err := client.ArchiveRepo(ctx, "me/ok"); err != nil {
+		t.Fatalf("archive ok: %v", err)
+	}
+	if err := client.DeleteRepo(ctx, "me/forbidden"); err == nil {
+		t.Fatalf("expected forbidden error")
+	}
+	if err := client.DeleteRepo(ctx, "me/missing"); err == nil {
+		t.Fatalf("expected not found error")
+	}
+	if _, err := client.GetRepo(ctx, "me/missing"); !errors.Is(err, gh.ErrNotFound) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+}
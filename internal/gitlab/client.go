@@ -0,0 +1,263 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/seeg/github-fork-manager/internal/gh"
+)
+
+// Client is a minimal GitLab client covering the operations the TUI needs.
+// Projects are addressed by their URL-encoded path_with_namespace.
+type Client struct {
+	BaseURL    string
+	Token      string
+	HTTPClient *http.Client
+}
+
+var _ gh.Provider = Client{}
+
+// New returns a Client with defaults applied. baseURL should include the
+// API prefix, e.g. https://gitlab.com/api/v4.
+func New(baseURL, token string) Client {
+	return Client{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		Token:   token,
+		HTTPClient: &http.Client{
+			Timeout: 15 * time.Second,
+		},
+	}
+}
+
+// FetchRepos retrieves projects owned by the authenticated user, optionally
+// restricted to forks. Projects already marked for deletion are skipped.
+func (c Client) FetchRepos(ctx context.Context, wantForks bool) ([]gh.Repo, error) {
+	if c.Token == "" {
+		return nil, errors.New("gitlab token not set")
+	}
+
+	var repos []gh.Repo
+	page := 1
+
+	for {
+		body, err := c.do(ctx, http.MethodGet, fmt.Sprintf("/projects?owned=true&statistics=true&per_page=100&page=%d", page), http.StatusOK)
+		if err != nil {
+			return nil, fmt.Errorf("list projects: %w", err)
+		}
+
+		var payload []apiProject
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, err
+		}
+
+		if len(payload) == 0 {
+			break
+		}
+
+		for _, p := range payload {
+			if p.markedForDeletion() {
+				continue
+			}
+			if wantForks != (p.ForkedFromProject != nil) {
+				continue
+			}
+			repos = append(repos, mapProject(p))
+		}
+
+		page++
+	}
+
+	return repos, nil
+}
+
+// GetRepo fetches a single project by full path.
+func (c Client) GetRepo(ctx context.Context, fullName string) (gh.Repo, error) {
+	p, err := c.getProject(ctx, fullName)
+	if err != nil {
+		return gh.Repo{}, err
+	}
+	return mapProject(p), nil
+}
+
+// DeleteRepo deletes a project by full path. GitLab may only mark the
+// project for delayed deletion; in that case gh.ErrDeleteScheduled is
+// returned so callers can report it distinctly.
+func (c Client) DeleteRepo(ctx context.Context, fullName string) error {
+	if c.Token == "" {
+		return errors.New("gitlab token not set")
+	}
+	p, err := c.getProject(ctx, fullName)
+	if err != nil {
+		return fmt.Errorf("delete %s: %w", fullName, err)
+	}
+	byID := fmt.Sprintf("/projects/%d", p.ID)
+	if _, err := c.do(ctx, http.MethodDelete, byID, http.StatusAccepted); err != nil {
+		return fmt.Errorf("delete %s: %w", fullName, err)
+	}
+
+	// The 202 looks the same for both modes; ask again to find out which
+	// one the server applied. Scheduled projects get renamed, so they are
+	// looked up by ID. Anything but a 404 or a deletion mark leaves the
+	// outcome unconfirmed.
+	body, err := c.do(ctx, http.MethodGet, byID, http.StatusOK)
+	if errors.Is(err, gh.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("delete %s: accepted, but the result could not be confirmed: %w", fullName, err)
+	}
+	var after apiProject
+	if err := json.Unmarshal(body, &after); err != nil {
+		return fmt.Errorf("delete %s: accepted, but the result could not be confirmed: %w", fullName, err)
+	}
+	if after.markedForDeletion() {
+		return gh.ErrDeleteScheduled
+	}
+	return fmt.Errorf("delete %s: accepted, but the project still exists and is not scheduled for deletion", fullName)
+}
+
+// ArchiveRepo archives a project.
+func (c Client) ArchiveRepo(ctx context.Context, fullName string) error {
+	if c.Token == "" {
+		return errors.New("gitlab token not set")
+	}
+	if _, err := c.do(ctx, http.MethodPost, projectPath(fullName)+"/archive", http.StatusCreated); err != nil {
+		return fmt.Errorf("archive %s: %w", fullName, err)
+	}
+	return nil
+}
+
+// CurrentUser fetches the username of the authenticated user.
+func (c Client) CurrentUser(ctx context.Context) (string, error) {
+	if c.Token == "" {
+		return "", errors.New("gitlab token not set")
+	}
+	body, err := c.do(ctx, http.MethodGet, "/user", http.StatusOK)
+	if err != nil {
+		return "", fmt.Errorf("whoami: %w", err)
+	}
+	var payload struct {
+		Username string `json:"username"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return "", err
+	}
+	return payload.Username, nil
+}
+
+func (c Client) getProject(ctx context.Context, fullName string) (apiProject, error) {
+	if c.Token == "" {
+		return apiProject{}, errors.New("gitlab token not set")
+	}
+	body, err := c.do(ctx, http.MethodGet, projectPath(fullName)+"?statistics=true", http.StatusOK)
+	if err != nil {
+		return apiProject{}, fmt.Errorf("get %s: %w", fullName, err)
+	}
+	var payload apiProject
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return apiProject{}, err
+	}
+	return payload, nil
+}
+
+// do sends a request and returns the body when the response status matches
+// want. Other statuses are turned into errors carrying the server message.
+func (c Client) do(ctx context.Context, method, path string, want int) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("PRIVATE-TOKEN", c.Token)
+	req.Header.Set("User-Agent", "github-fork-manager")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == want:
+		return body, nil
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, gh.ErrNotFound
+	case resp.StatusCode == http.StatusForbidden:
+		return nil, fmt.Errorf("forbidden: %s", strings.TrimSpace(string(body)))
+	}
+	return nil, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+}
+
+func projectPath(fullName string) string {
+	return "/projects/" + url.PathEscape(fullName)
+}
+
+type apiProject struct {
+	ID                  int64     `json:"id"`
+	Path                string    `json:"path"`
+	PathWithNamespace   string    `json:"path_with_namespace"`
+	Visibility          string    `json:"visibility"`
+	Archived            bool      `json:"archived"`
+	DefaultBranch       string    `json:"default_branch"`
+	LastActivityAt      time.Time `json:"last_activity_at"`
+	WebURL              string    `json:"web_url"`
+	SSHURL              string    `json:"ssh_url_to_repo"`
+	MarkedForDeletionAt string    `json:"marked_for_deletion_at"`
+	MarkedForDeletionOn string    `json:"marked_for_deletion_on"`
+	Namespace           struct {
+		FullPath string `json:"full_path"`
+	} `json:"namespace"`
+	ForkedFromProject *struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"forked_from_project"`
+	Statistics *struct {
+		RepositorySize int64 `json:"repository_size"`
+	} `json:"statistics"`
+}
+
+// markedForDeletion reports whether the project is pending delayed
+// deletion. Older servers use marked_for_deletion_at, newer ones _on.
+func (p apiProject) markedForDeletion() bool {
+	return p.MarkedForDeletionAt != "" || p.MarkedForDeletionOn != ""
+}
+
+// mapProject converts a GitLab project into the shared repo model. Internal
+// visibility counts as private, and the size is converted from bytes to KB
+// to match GitHub.
+func mapProject(p apiProject) gh.Repo {
+	parent := ""
+	if p.ForkedFromProject != nil {
+		parent = p.ForkedFromProject.PathWithNamespace
+	}
+	size := 0
+	if p.Statistics != nil {
+		size = int(p.Statistics.RepositorySize / 1024)
+	}
+
+	return gh.Repo{
+		ID:            p.ID,
+		Name:          p.Path,
+		FullName:      p.PathWithNamespace,
+		Owner:         p.Namespace.FullPath,
+		Private:       p.Visibility != "public",
+		Archived:      p.Archived,
+		Fork:          p.ForkedFromProject != nil,
+		Size:          size,
+		DefaultBranch: p.DefaultBranch,
+		Parent:        parent,
+		PushedAt:      p.LastActivityAt,
+		HTMLURL:       p.WebURL,
+		SSHURL:        p.SSHURL,
+	}
+}
@@ -0,0 +1,114 @@
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/seeg/github-fork-manager/internal/gh"
+)
+
+func TestFetchReposMapsForks(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "secret" {
+			t.Errorf("missing token header")
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		if r.URL.Path != "/projects" || r.URL.Query().Get("owned") != "true" {
+			t.Errorf("unexpected request %s", r.URL)
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		if r.URL.Query().Get("page") != "1" {
+			w.Write([]byte(`[]`))
+			return
+		}
+		w.Write([]byte(`[
+			{"id":1,"path":"tool","path_with_namespace":"me/tool","visibility":"internal","archived":true,
+			 "last_activity_at":"2024-05-06T07:08:09Z","namespace":{"full_path":"me"},
+			 "forked_from_project":{"path_with_namespace":"up/tool"},"statistics":{"repository_size":2048}},
+			{"id":2,"path":"own","path_with_namespace":"me/own","visibility":"public","namespace":{"full_path":"me"}},
+			{"id":3,"path":"gone","path_with_namespace":"me/gone","namespace":{"full_path":"me"},
+			 "forked_from_project":{"path_with_namespace":"up/gone"},"marked_for_deletion_on":"2024-06-01"}
+		]`))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, "secret")
+	forks, err := client.FetchRepos(context.Background(), true)
+	if err != nil {
+		t.Fatalf("fetch forks: %v", err)
+	}
+	if len(forks) != 1 {
+		t.Fatalf("expected one live fork, got %#v", forks)
+	}
+	got := forks[0]
+	if got.FullName != "me/tool" || got.Parent != "up/tool" || !got.Private || !got.Archived || got.Size != 2 || got.PushedAt.IsZero() {
+		t.Fatalf("unexpected mapping: %#v", got)
+	}
+
+	owned, err := client.FetchRepos(context.Background(), false)
+	if err != nil {
+		t.Fatalf("fetch owned: %v", err)
+	}
+	if len(owned) != 1 || owned[0].FullName != "me/own" || owned[0].Private {
+		t.Fatalf("unexpected owned projects: %#v", owned)
+	}
+}
+
+func TestDeleteRepoReportsDelayedDeletion(t *testing.T) {
+	deleted := map[string]bool{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.EscapedPath() {
+		case "GET /projects/me%2Fnow":
+			w.Write([]byte(`{"id":1,"path_with_namespace":"me/now"}`))
+		case "GET /projects/me%2Flater":
+			w.Write([]byte(`{"id":2,"path_with_namespace":"me/later"}`))
+		case "GET /projects/me%2Fflaky":
+			w.Write([]byte(`{"id":3,"path_with_namespace":"me/flaky"}`))
+		case "GET /projects/me%2Fkept":
+			w.Write([]byte(`{"id":4,"path_with_namespace":"me/kept"}`))
+		case "DELETE /projects/1":
+			deleted["now"] = true
+			w.WriteHeader(http.StatusAccepted)
+		case "DELETE /projects/2", "DELETE /projects/3", "DELETE /projects/4":
+			w.WriteHeader(http.StatusAccepted)
+		case "GET /projects/2":
+			// Scheduled projects are renamed, so only the ID still finds it.
+			w.Write([]byte(`{"id":2,"path_with_namespace":"me/later-deletion_scheduled-2","marked_for_deletion_on":"2024-06-01"}`))
+		case "GET /projects/3":
+			http.Error(w, "upstream timeout", http.StatusBadGateway)
+		case "GET /projects/4":
+			w.Write([]byte(`{"id":4,"path_with_namespace":"me/kept"}`))
+		case "POST /projects/me%2Fnow/archive":
+			w.WriteHeader(http.StatusCreated)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, "secret")
+	ctx := context.Background()
+
+	if err := client.DeleteRepo(ctx, "me/now"); err != nil || !deleted["now"] {
+		t.Fatalf("expected immediate delete, got %v", err)
+	}
+	if err := client.DeleteRepo(ctx, "me/later"); !errors.Is(err, gh.ErrDeleteScheduled) {
+		t.Fatalf("expected scheduled deletion, got %v", err)
+	}
+	if err := client.DeleteRepo(ctx, "me/missing"); !errors.Is(err, gh.ErrNotFound) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+	for _, name := range []string{"me/flaky", "me/kept"} {
+		if err := client.DeleteRepo(ctx, name); err == nil || errors.Is(err, gh.ErrDeleteScheduled) {
+			t.Fatalf("%s: expected an unconfirmed delete to fail, got %v", name, err)
+		}
+	}
+	if err := client.ArchiveRepo(ctx, "me/now"); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+}
@@ -0,0 +1,178 @@
+// Package policy evaluates declarative cleanup rules against fetched repos
+// for unattended runs.
+package policy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/seeg/github-fork-manager/internal/gh"
+)
+
+// Action is what a matching rule does to a repo.
+type Action string
+
+const (
+	ActionReport  Action = "report"
+	ActionArchive Action = "archive"
+	ActionDelete  Action = "delete"
+)
+
+// Policy is a cleanup policy file. Rules are evaluated in order and the
+// first match wins; repos matching a Protected pattern are never acted on.
+type Policy struct {
+	// Scope selects "forks" (default) or "non-forks".
+	Scope     string   `yaml:"scope"`
+	Protected []string `yaml:"protected"`
+	Rules     []Rule   `yaml:"rules"`
+}
+
+// Rule pairs match conditions with an action. All set conditions must hold.
+type Rule struct {
+	Name   string `yaml:"name"`
+	Action Action `yaml:"action"`
+	Match  Match  `yaml:"match"`
+}
+
+// Match lists the conditions a rule can test. Unset fields match anything.
+type Match struct {
+	PushedOlderThanDays int    `yaml:"pushed_older_than_days"`
+	Archived            *bool  `yaml:"archived"`
+	Private             *bool  `yaml:"private"`
+	Language            string `yaml:"language"`
+	Name                string `yaml:"name"`
+	// NoUniqueCommits requires a unique-commit scan reporting nothing
+	// unique; unknown scan results never match.
+	NoUniqueCommits bool `yaml:"no_unique_commits"`
+}
+
+// Decision is the outcome of evaluating one repo.
+type Decision struct {
+	Repo      gh.Repo
+	Rule      string
+	Action    Action
+	Protected bool
+}
+
+// Load reads and validates a policy file. YAML is expected; JSON works too
+// since it is valid YAML. Unknown keys are errors: a misspelt condition
+// would otherwise be dropped and widen its rule.
+func Load(p string) (Policy, error) {
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return Policy{}, fmt.Errorf("read policy: %w", err)
+	}
+	var pol Policy
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&pol); err != nil && !errors.Is(err, io.EOF) {
+		return Policy{}, fmt.Errorf("parse policy: %w", err)
+	}
+	return pol, pol.Validate()
+}
+
+// Validate checks the scope, actions and patterns. Rules that change repos
+// need at least one match condition, so they never apply to every repo.
+func (p Policy) Validate() error {
+	switch p.Scope {
+	case "", "forks", "non-forks":
+	default:
+		return fmt.Errorf("unknown scope %q (want forks or non-forks)", p.Scope)
+	}
+	if len(p.Rules) == 0 {
+		return errors.New("policy has no rules")
+	}
+	for i, r := range p.Rules {
+		switch r.Action {
+		case ActionReport, ActionArchive, ActionDelete:
+		default:
+			return fmt.Errorf("rule %d (%s): unknown action %q", i+1, r.Name, r.Action)
+		}
+		if r.Action != ActionReport && r.Match == (Match{}) {
+			return fmt.Errorf("rule %d (%s): %s rule without match conditions would %s every repo", i+1, r.Name, r.Action, r.Action)
+		}
+		if r.Match.Name != "" {
+			if _, err := path.Match(r.Match.Name, ""); err != nil {
+				return fmt.Errorf("rule %d (%s): bad name pattern: %w", i+1, r.Name, err)
+			}
+		}
+	}
+	for _, pat := range p.Protected {
+		if _, err := path.Match(pat, ""); err != nil {
+			return fmt.Errorf("bad protected pattern %q: %w", pat, err)
+		}
+	}
+	return nil
+}
+
+// Forks reports whether the policy targets forks.
+func (p Policy) Forks() bool {
+	return p.Scope != "non-forks"
+}
+
+// Evaluate applies the rules to repos. unique is consulted lazily, only for
+// repos that pass every other condition of a no_unique_commits rule; it may
+// be nil when the provider cannot scan. Repos matching no rule are left out.
+func (p Policy) Evaluate(repos []gh.Repo, now time.Time, unique func(gh.Repo) gh.Uniqueness) []Decision {
+	var out []Decision
+	for _, repo := range repos {
+		for _, rule := range p.Rules {
+			if !rule.Match.matches(repo, now, unique) {
+				continue
+			}
+			d := Decision{Repo: repo, Rule: rule.Name, Action: rule.Action}
+			if p.protected(repo.FullName) {
+				d.Protected = true
+				d.Action = ActionReport
+			}
+			out = append(out, d)
+			break
+		}
+	}
+	return out
+}
+
+func (p Policy) protected(fullName string) bool {
+	for _, pat := range p.Protected {
+		if ok, _ := path.Match(strings.ToLower(pat), strings.ToLower(fullName)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (m Match) matches(repo gh.Repo, now time.Time, unique func(gh.Repo) gh.Uniqueness) bool {
+	if m.PushedOlderThanDays > 0 {
+		if repo.PushedAt.IsZero() || now.Sub(repo.PushedAt) < time.Duration(m.PushedOlderThanDays)*24*time.Hour {
+			return false
+		}
+	}
+	if m.Archived != nil && repo.Archived != *m.Archived {
+		return false
+	}
+	if m.Private != nil && repo.Private != *m.Private {
+		return false
+	}
+	if m.Language != "" && !strings.EqualFold(m.Language, repo.Language) {
+		return false
+	}
+	if m.Name != "" {
+		if ok, _ := path.Match(strings.ToLower(m.Name), strings.ToLower(repo.FullName)); !ok {
+			return false
+		}
+	}
+	if m.NoUniqueCommits {
+		if unique == nil || unique(repo).State != gh.UniqueNone {
+			return false
+		}
+	}
+	return true
+}
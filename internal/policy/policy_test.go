@@ -0,0 +1,101 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/seeg/github-fork-manager/internal/gh"
+)
+
+func TestLoadValidatesPolicy(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.yaml")
+	os.WriteFile(good, []byte(`
+protected: ["me/keep-*"]
+rules:
+  - name: stale forks
+    action: delete
+    match:
+      pushed_older_than_days: 365
+      no_unique_commits: true
+  - name: archived
+    action: archive
+    match:
+      archived: true
+`), 0o644)
+	pol, err := Load(good)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if !pol.Forks() || len(pol.Rules) != 2 || pol.Rules[0].Match.PushedOlderThanDays != 365 {
+		t.Fatalf("unexpected policy: %#v", pol)
+	}
+
+	bad := filepath.Join(dir, "bad.yaml")
+	os.WriteFile(bad, []byte("rules:\n  - name: x\n    action: explode\n"), 0o644)
+	if _, err := Load(bad); err == nil {
+		t.Fatalf("expected unknown action error")
+	}
+
+	for name, body := range map[string]string{
+		"typo":  "rules:\n  - name: x\n    action: delete\n    match:\n      pushed_older_then_days: 30\n",
+		"empty": "rules:\n  - name: x\n    action: delete\n    match:\n",
+		"none":  "rules:\n  - name: x\n    action: archive\n",
+	} {
+		file := filepath.Join(dir, name+".yaml")
+		os.WriteFile(file, []byte(body), 0o644)
+		if _, err := Load(file); err == nil {
+			t.Fatalf("%s: expected the rule to be rejected", name)
+		}
+	}
+	report := filepath.Join(dir, "report.yaml")
+	os.WriteFile(report, []byte("rules:\n  - name: everything\n    action: report\n"), 0o644)
+	if _, err := Load(report); err != nil {
+		t.Fatalf("report rules may match everything: %v", err)
+	}
+}
+
+func TestEvaluateFirstMatchWinsAndProtects(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	yes := true
+	pol := Policy{
+		Protected: []string{"me/keep-*"},
+		Rules: []Rule{
+			{Name: "stale", Action: ActionDelete, Match: Match{PushedOlderThanDays: 365, NoUniqueCommits: true}},
+			{Name: "archived", Action: ActionArchive, Match: Match{Archived: &yes}},
+		},
+	}
+	repos := []gh.Repo{
+		{FullName: "me/old", PushedAt: now.AddDate(-2, 0, 0)},
+		{FullName: "me/old-unique", PushedAt: now.AddDate(-2, 0, 0), Archived: true},
+		{FullName: "me/keep-this", PushedAt: now.AddDate(-2, 0, 0)},
+		{FullName: "me/fresh", PushedAt: now.AddDate(0, -1, 0)},
+	}
+	scanned := map[string]bool{}
+	unique := func(r gh.Repo) gh.Uniqueness {
+		scanned[r.FullName] = true
+		if r.FullName == "me/old-unique" {
+			return gh.Uniqueness{State: gh.UniqueFound, Refs: []string{"wip"}}
+		}
+		return gh.Uniqueness{State: gh.UniqueNone}
+	}
+
+	got := pol.Evaluate(repos, now, unique)
+	if len(got) != 3 {
+		t.Fatalf("expected 3 decisions, got %#v", got)
+	}
+	if got[0].Repo.FullName != "me/old" || got[0].Action != ActionDelete {
+		t.Fatalf("expected me/old deleted, got %#v", got[0])
+	}
+	if got[1].Repo.FullName != "me/old-unique" || got[1].Action != ActionArchive {
+		t.Fatalf("expected unique work to fall through to archive, got %#v", got[1])
+	}
+	if !got[2].Protected || got[2].Action != ActionReport {
+		t.Fatalf("expected protected repo downgraded to report, got %#v", got[2])
+	}
+	if scanned["me/fresh"] {
+		t.Fatalf("scan should be skipped when other conditions fail")
+	}
+}
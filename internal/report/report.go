@@ -0,0 +1,255 @@
+// Package report renders selections and batch results as Markdown, CSV or
+// JSON for audit trails.
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/seeg/github-fork-manager/internal/gh"
+)
+
+// Format is an output format.
+type Format string
+
+const (
+	Markdown Format = "markdown"
+	CSV      Format = "csv"
+	JSON     Format = "json"
+)
+
+// ParseFormat accepts a format name or a common alias (md).
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "markdown", "md":
+		return Markdown, nil
+	case "csv":
+		return CSV, nil
+	case "json":
+		return JSON, nil
+	}
+	return "", fmt.Errorf("unknown report format %q (want markdown, csv or json)", s)
+}
+
+// FormatForPath picks the format from a file extension, defaulting to
+// Markdown.
+func FormatForPath(path string) Format {
+	if f, err := ParseFormat(strings.TrimPrefix(filepath.Ext(path), ".")); err == nil {
+		return f
+	}
+	return Markdown
+}
+
+// Entry is one repo in a report. Result is empty for plain selections.
+type Entry struct {
+	FullName string    `json:"full_name"`
+	URL      string    `json:"url,omitempty"`
+	Language string    `json:"language,omitempty"`
+	Parent   string    `json:"parent,omitempty"`
+	Private  bool      `json:"private"`
+	Archived bool      `json:"archived"`
+	Fork     bool      `json:"fork"`
+	SizeKB   int       `json:"size_kb"`
+	PushedAt time.Time `json:"pushed_at"`
+	Result   string    `json:"result,omitempty"`
+	At       time.Time `json:"at"`
+}
+
+// NewEntry describes repo with the given result recorded at at.
+func NewEntry(repo gh.Repo, result string, at time.Time) Entry {
+	return Entry{
+		FullName: repo.FullName,
+		URL:      repo.HTMLURL,
+		Language: repo.Language,
+		Parent:   repo.Parent,
+		Private:  repo.Private,
+		Archived: repo.Archived,
+		Fork:     repo.Fork,
+		SizeKB:   repo.Size,
+		PushedAt: repo.PushedAt,
+		Result:   result,
+		At:       at,
+	}
+}
+
+// Report is a titled list of entries.
+type Report struct {
+	Title       string    `json:"title"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Summary     Summary   `json:"summary"`
+	Entries     []Entry   `json:"entries"`
+}
+
+// Summary counts entries by outcome and totals the size of removed repos.
+type Summary struct {
+	Total       int            `json:"total"`
+	ByResult    map[string]int `json:"by_result"`
+	SizeFreedKB int            `json:"size_freed_kb"`
+}
+
+// New builds a report and its summary.
+func New(title string, entries []Entry, now time.Time) Report {
+	s := Summary{Total: len(entries), ByResult: make(map[string]int)}
+	for _, e := range entries {
+		outcome := Outcome(e.Result)
+		s.ByResult[outcome]++
+		if outcome == "deleted" || outcome == "scheduled for deletion" {
+			s.SizeFreedKB += e.SizeKB
+		}
+	}
+	return Report{Title: title, GeneratedAt: now, Summary: s, Entries: entries}
+}
+
+// Outcome reduces a result to the category it is counted under, e.g.
+// "error: forbidden" to "error" and "quarantined as me/x" to
+// "quarantined". Empty results count as "selected".
+func Outcome(result string) string {
+	if result == "" {
+		return "selected"
+	}
+	if before, _, ok := strings.Cut(result, ":"); ok {
+		result = before
+	}
+	if before, _, ok := strings.Cut(result, " as "); ok {
+		result = before
+	}
+	return result
+}
+
+// Read parses a report previously written as JSON.
+func Read(r io.Reader) (Report, error) {
+	var rep Report
+	if err := json.NewDecoder(r).Decode(&rep); err != nil {
+		return Report{}, fmt.Errorf("parse report: %w", err)
+	}
+	return rep, nil
+}
+
+// Write renders rep in format f.
+func Write(w io.Writer, f Format, rep Report) error {
+	switch f {
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rep)
+	case CSV:
+		return writeCSV(w, rep)
+	case Markdown:
+		return writeMarkdown(w, rep)
+	}
+	return fmt.Errorf("unknown report format %q", f)
+}
+
+// outcomes lists summary categories, most frequent first.
+func (s Summary) outcomes() []string {
+	names := make([]string, 0, len(s.ByResult))
+	for name := range s.ByResult {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if s.ByResult[names[i]] != s.ByResult[names[j]] {
+			return s.ByResult[names[i]] > s.ByResult[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+func writeMarkdown(w io.Writer, rep Report) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", rep.Title)
+	fmt.Fprintf(&b, "Generated %s\n\n", rep.GeneratedAt.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "- Repos: %d\n", rep.Summary.Total)
+	for _, name := range rep.Summary.outcomes() {
+		fmt.Fprintf(&b, "- %s: %d\n", name, rep.Summary.ByResult[name])
+	}
+	fmt.Fprintf(&b, "- Size freed: %s\n\n", HumanSize(rep.Summary.SizeFreedKB))
+	b.WriteString("| Repo | Result | Parent | Language | Visibility | Size | Last push | At |\n")
+	b.WriteString("|---|---|---|---|---|---|---|---|\n")
+	for _, e := range rep.Entries {
+		visibility := "public"
+		if e.Private {
+			visibility = "private"
+		}
+		if e.Archived {
+			visibility += ", archived"
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s | %s | %s |\n",
+			mdCell(e.FullName), mdCell(orDash(e.Result)), mdCell(orDash(e.Parent)), mdCell(orDash(e.Language)),
+			visibility, HumanSize(e.SizeKB), date(e.PushedAt), stamp(e.At))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeCSV(w io.Writer, rep Report) error {
+	// Summary lines come first as comments so the table stays importable.
+	fmt.Fprintf(w, "# %s, generated %s\n", rep.Title, rep.GeneratedAt.UTC().Format(time.RFC3339))
+	fmt.Fprintf(w, "# total: %d\n", rep.Summary.Total)
+	for _, name := range rep.Summary.outcomes() {
+		fmt.Fprintf(w, "# %s: %d\n", name, rep.Summary.ByResult[name])
+	}
+	fmt.Fprintf(w, "# size_freed_kb: %d\n", rep.Summary.SizeFreedKB)
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"full_name", "result", "parent", "language", "private", "archived", "fork", "size_kb", "pushed_at", "at", "url"})
+	for _, e := range rep.Entries {
+		cw.Write([]string{
+			e.FullName, e.Result, e.Parent, e.Language,
+			strconv.FormatBool(e.Private), strconv.FormatBool(e.Archived), strconv.FormatBool(e.Fork),
+			strconv.Itoa(e.SizeKB), rfc3339(e.PushedAt), rfc3339(e.At), e.URL,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// HumanSize formats a size in KB, the unit GitHub reports.
+func HumanSize(kb int) string {
+	switch {
+	case kb >= 1024*1024:
+		return fmt.Sprintf("%.1f GB", float64(kb)/(1024*1024))
+	case kb >= 1024:
+		return fmt.Sprintf("%.1f MB", float64(kb)/1024)
+	}
+	return fmt.Sprintf("%d KB", kb)
+}
+
+func mdCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func date(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.UTC().Format("2006-01-02")
+}
+
+func stamp(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func rfc3339(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,93 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/seeg/github-fork-manager/internal/gh"
+)
+
+func sample() Report {
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	pushed := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		NewEntry(gh.Repo{FullName: "me/a", Parent: "up/a", Fork: true, Size: 2048, PushedAt: pushed}, "deleted", at),
+		NewEntry(gh.Repo{FullName: "me/b", Parent: "up/b", Fork: true, Size: 512, Language: "Go"}, "deleted", at),
+		NewEntry(gh.Repo{FullName: "me/c|d", Private: true, Size: 100}, "error: forbidden", at),
+		NewEntry(gh.Repo{FullName: "me/e", Size: 10}, "quarantined as me/zz-e", at),
+	}
+	return New("Fork cleanup report", entries, at)
+}
+
+func TestSummaryCountsOutcomesAndFreedSize(t *testing.T) {
+	s := sample().Summary
+	if s.Total != 4 || s.ByResult["deleted"] != 2 || s.ByResult["error"] != 1 || s.ByResult["quarantined"] != 1 {
+		t.Fatalf("unexpected summary: %#v", s)
+	}
+	if s.SizeFreedKB != 2560 {
+		t.Fatalf("expected 2560 KB freed, got %d", s.SizeFreedKB)
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	var b bytes.Buffer
+	if err := Write(&b, Markdown, sample()); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		"# Fork cleanup report",
+		"- deleted: 2\n- error: 1\n- quarantined: 1\n- Size freed: 2.5 MB",
+		"| me/a | deleted | up/a | - | public | 2.0 MB | 2023-01-02 | 2024-03-01T12:00:00Z |",
+		`| me/c\|d | error: forbidden |`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("markdown missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteCSVKeepsSummaryAsComments(t *testing.T) {
+	var b bytes.Buffer
+	if err := Write(&b, CSV, sample()); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if !strings.Contains(b.String(), "# deleted: 2\n# error: 1\n# quarantined: 1\n# size_freed_kb: 2560\n") {
+		t.Fatalf("expected summary comments, got:\n%s", b.String())
+	}
+	r := csv.NewReader(&b)
+	r.Comment = '#'
+	rows, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	if len(rows) != 5 || rows[0][0] != "full_name" || rows[1][1] != "deleted" || rows[1][8] != "2023-01-02T00:00:00Z" {
+		t.Fatalf("unexpected rows: %v", rows)
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	var b bytes.Buffer
+	if err := Write(&b, JSON, sample()); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	rep, err := Read(&b)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if len(rep.Entries) != 4 || rep.Summary.SizeFreedKB != 2560 || rep.Entries[3].Result != "quarantined as me/zz-e" {
+		t.Fatalf("unexpected round trip: %#v", rep)
+	}
+}
+
+func TestFormatForPath(t *testing.T) {
+	cases := map[string]Format{"out.csv": CSV, "out.JSON": JSON, "out.md": Markdown, "out": Markdown}
+	for path, want := range cases {
+		if got := FormatForPath(path); got != want {
+			t.Fatalf("%s: expected %s, got %s", path, want, got)
+		}
+	}
+}
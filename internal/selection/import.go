@@ -0,0 +1,98 @@
+package selection
+
+import (
+	"bufio"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/seeg/github-fork-manager/internal/gh"
+)
+
+// ParseList reads one entry per line: a full name (owner/name), a repo URL
+// (https or SSH) or a numeric repo ID. Blank lines and lines starting with
+// # are skipped. Spreadsheet exports work too: only the first comma- or
+// tab-separated column is read.
+func ParseList(r io.Reader) ([]string, error) {
+	var entries []string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if i := strings.IndexAny(line, ",\t"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		line = strings.Trim(line, `"`)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entries = append(entries, line)
+	}
+	return entries, sc.Err()
+}
+
+// Match resolves entries against repos. Each repo is returned once, in the
+// order of its first matching entry; entries matching nothing are returned
+// as unmatched.
+func Match(entries []string, repos []gh.Repo) (matched []gh.Repo, unmatched []string) {
+	byName := make(map[string]gh.Repo, len(repos))
+	byID := make(map[int64]gh.Repo, len(repos))
+	for _, r := range repos {
+		byName[strings.ToLower(r.FullName)] = r
+		if r.ID != 0 {
+			byID[r.ID] = r
+		}
+	}
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		repo, ok := lookup(entry, byName, byID)
+		if !ok {
+			unmatched = append(unmatched, entry)
+			continue
+		}
+		if !seen[repo.FullName] {
+			seen[repo.FullName] = true
+			matched = append(matched, repo)
+		}
+	}
+	return matched, unmatched
+}
+
+func lookup(entry string, byName map[string]gh.Repo, byID map[int64]gh.Repo) (gh.Repo, bool) {
+	if id, err := strconv.ParseInt(entry, 10, 64); err == nil {
+		r, ok := byID[id]
+		return r, ok
+	}
+	path, browser := entry, false
+	switch {
+	case strings.HasPrefix(entry, "git@"):
+		if _, after, ok := strings.Cut(entry, ":"); ok {
+			path = after
+		}
+	case strings.Contains(entry, "://"):
+		u, err := url.Parse(entry)
+		if err != nil {
+			return gh.Repo{}, false
+		}
+		path, browser = u.Path, true
+	}
+	path = strings.ToLower(strings.TrimSuffix(strings.Trim(path, "/"), ".git"))
+	if r, ok := byName[path]; ok {
+		return r, true
+	}
+	if !browser {
+		return gh.Repo{}, false
+	}
+	// Browser URLs often point below the repo, e.g. .../tree/main or
+	// GitLab's .../-/blob/main/README.md. Nothing else is cut short: a
+	// stale or subgroup path must not fall back to a parent path's repo.
+	parts := strings.Split(path, "/")
+	for n := 2; n < len(parts); n++ {
+		switch parts[n] {
+		case "-", "tree", "blob":
+			r, ok := byName[strings.Join(parts[:n], "/")]
+			return r, ok
+		}
+	}
+	return gh.Repo{}, false
+}
@@ -0,0 +1,53 @@
+package selection
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/seeg/github-fork-manager/internal/gh"
+)
+
+func TestParseListSkipsCommentsAndExtraColumns(t *testing.T) {
+	input := "# forks to drop\nme/a\n\n\"https://github.com/me/b\",deprecated,2024\n42\tnote\n"
+	entries, err := ParseList(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	want := []string{"me/a", "https://github.com/me/b", "42"}
+	if strings.Join(entries, "|") != strings.Join(want, "|") {
+		t.Fatalf("expected %v, got %v", want, entries)
+	}
+}
+
+func TestMatchNamesURLsAndIDs(t *testing.T) {
+	repos := []gh.Repo{
+		{ID: 1, FullName: "me/a"},
+		{ID: 2, FullName: "me/b"},
+		{ID: 42, FullName: "me/c"},
+		{ID: 7, FullName: "group/sub/project"},
+		{ID: 8, FullName: "group/sub"},
+	}
+	entries := []string{
+		"Me/A",
+		"https://github.com/me/b/tree/main",
+		"git@github.com:me/a.git",
+		"42",
+		"https://gitlab.com/group/sub/project/-/blob/main/README.md",
+		"me/gone",
+		"99",
+		"me/a/extra",
+		"group/sub/gone",
+		"https://gitlab.com/group/sub/gone",
+	}
+	matched, unmatched := Match(entries, repos)
+	var names []string
+	for _, r := range matched {
+		names = append(names, r.FullName)
+	}
+	if got := strings.Join(names, ","); got != "me/a,me/b,me/c,group/sub/project" {
+		t.Fatalf("unexpected matches: %s", got)
+	}
+	if strings.Join(unmatched, ",") != "me/gone,99,me/a/extra,group/sub/gone,https://gitlab.com/group/sub/gone" {
+		t.Fatalf("unexpected unmatched: %v", unmatched)
+	}
+}
@@ -0,0 +1,128 @@
+// Package selection persists named filter and selection sets so a cleanup
+// review can span several sessions or be handed to a teammate.
+package selection
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Set is a saved filter plus the full names of the selected repos.
+type Set struct {
+	Name     string    `json:"name"`
+	Filter   string    `json:"filter,omitempty"`
+	Selected []string  `json:"selected"`
+	SavedAt  time.Time `json:"saved_at"`
+}
+
+// Store keeps one JSON file per set in Dir.
+type Store struct {
+	Dir string
+}
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ErrNotFound is returned by Load for unknown set names.
+var ErrNotFound = errors.New("no saved selection")
+
+// Save writes set, replacing any set with the same name. The file is
+// written to a temporary name first so a crash never leaves it truncated.
+func (s Store) Save(set Set) error {
+	if err := checkName(set.Name); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return err
+	}
+	selected := append([]string{}, set.Selected...)
+	sort.Strings(selected)
+	set.Selected = selected
+	data, err := json.MarshalIndent(set, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.Dir, "."+set.Name+"-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(set.Name))
+}
+
+// Load reads the set called name.
+func (s Store) Load(name string) (Set, error) {
+	if err := checkName(name); err != nil {
+		return Set{}, err
+	}
+	data, err := os.ReadFile(s.path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return Set{}, fmt.Errorf("%w named %q", ErrNotFound, name)
+	}
+	if err != nil {
+		return Set{}, err
+	}
+	var set Set
+	if err := json.Unmarshal(data, &set); err != nil {
+		return Set{}, fmt.Errorf("parse selection %q: %w", name, err)
+	}
+	set.Name = name
+	return set, nil
+}
+
+// List returns the names of all saved sets, sorted.
+func (s Store) List() ([]string, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".json")
+		if ok && !e.IsDir() && validName.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// Reconcile splits the set's selection into names present in existing and
+// names that no longer exist.
+func (set Set) Reconcile(existing map[string]bool) (kept, missing []string) {
+	for _, name := range set.Selected {
+		if existing[name] {
+			kept = append(kept, name)
+			continue
+		}
+		missing = append(missing, name)
+	}
+	return kept, missing
+}
+
+func (s Store) path(name string) string {
+	return filepath.Join(s.Dir, name+".json")
+}
+
+func checkName(name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid selection name %q: use letters, digits, '.', '_' or '-'", name)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package selection
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveLoadList(t *testing.T) {
+	store := Store{Dir: filepath.Join(t.TempDir(), "selections")}
+	if names, err := store.List(); err != nil || len(names) != 0 {
+		t.Fatalf("expected empty list before first save, got %v %v", names, err)
+	}
+
+	saved := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	err := store.Save(Set{Name: "review-march", Filter: "go", Selected: []string{"me/b", "me/a"}, SavedAt: saved})
+	if err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if err := store.Save(Set{Name: "other"}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	set, err := store.Load("review-march")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if set.Filter != "go" || len(set.Selected) != 2 || set.Selected[0] != "me/a" || !set.SavedAt.Equal(saved) {
+		t.Fatalf("unexpected set: %#v", set)
+	}
+	names, err := store.List()
+	if err != nil || len(names) != 2 || names[0] != "other" || names[1] != "review-march" {
+		t.Fatalf("unexpected list: %v %v", names, err)
+	}
+
+	if _, err := store.Load("missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+	if err := store.Save(Set{Name: "../escape"}); err == nil {
+		t.Fatalf("expected invalid name error")
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	set := Set{Selected: []string{"me/a", "me/gone", "me/b"}}
+	kept, missing := set.Reconcile(map[string]bool{"me/a": true, "me/b": true})
+	if len(kept) != 2 || len(missing) != 1 || missing[0] != "me/gone" {
+		t.Fatalf("unexpected reconcile: kept %v missing %v", kept, missing)
+	}
+}